@@ -1,18 +1,31 @@
 package cli
 
 import (
+	"bytes"
 	"fmt"
+	"io"
 	"log"
+	"math/rand/v2"
 	"os"
 	"path/filepath"
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/ebook-renamer/go/internal/clipboard"
 	"github.com/ebook-renamer/go/internal/duplicates"
+	"github.com/ebook-renamer/go/internal/i18n"
+	"github.com/ebook-renamer/go/internal/index"
+	"github.com/ebook-renamer/go/internal/journal"
 	"github.com/ebook-renamer/go/internal/jsonoutput"
+	"github.com/ebook-renamer/go/internal/lock"
 	"github.com/ebook-renamer/go/internal/normalizer"
+	"github.com/ebook-renamer/go/internal/review"
 	"github.com/ebook-renamer/go/internal/scanner"
+	"github.com/ebook-renamer/go/internal/state"
+	"github.com/ebook-renamer/go/internal/summary"
+	"github.com/ebook-renamer/go/internal/tiers"
 	"github.com/ebook-renamer/go/internal/todo"
 	"github.com/ebook-renamer/go/internal/tui"
 	"github.com/ebook-renamer/go/internal/types"
@@ -36,6 +49,19 @@ var (
 	autoCleanupFlag     bool
 	jsonFlag            bool
 	skipCloudHashFlag   bool
+	sampleFlag          int
+	confidenceTiersFlag bool
+	autoThresholdFlag   float64
+	reviewThresholdFlag float64
+	mergeMetadataFlag   bool
+	portableFlag        bool
+	breakLockFlag       bool
+	noUpdateCheckFlag   bool
+	nearDuplicatesFlag  bool
+	langFlag            string
+	reasonCodesFlag     bool
+	copyFlag            string
+	oneLineFlag         bool
 )
 
 var rootCmd = &cobra.Command{
@@ -45,8 +71,9 @@ var rootCmd = &cobra.Command{
 
 This tool scans a directory for ebook files, normalizes their filenames,
 detects duplicates, and generates a todo.md file for manual review.`,
-	Args: cobra.MaximumNArgs(1),
-	RunE: runEbookRenamer,
+	Args:    cobra.MaximumNArgs(1),
+	RunE:    runEbookRenamer,
+	Version: Version,
 }
 
 func init() {
@@ -68,6 +95,19 @@ func init() {
 	rootCmd.Flags().BoolVar(&autoCleanupFlag, "auto-cleanup", false, "Automatically clean up incomplete downloads (.download/.crdownload) and corrupted files")
 	rootCmd.Flags().BoolVar(&jsonFlag, "json", false, "Output operations in JSON format instead of human-readable text")
 	rootCmd.Flags().BoolVar(&skipCloudHashFlag, "skip-cloud-hash", false, "Skip MD5 hash computation for duplicate detection (useful for cloud storage like Dropbox to avoid triggering file downloads)")
+	rootCmd.Flags().IntVar(&sampleFlag, "sample", 0, "Before applying, show N randomly sampled operations for approval; if accepted, all operations are applied")
+	rootCmd.Flags().BoolVar(&confidenceTiersFlag, "confidence-tiers", false, "Bucket operations by confidence: apply high-confidence ones, send medium ones to todo.md for review and skip the rest")
+	rootCmd.Flags().Float64Var(&autoThresholdFlag, "auto-threshold", tiers.DefaultAutoThreshold, "Minimum confidence for an operation to be applied automatically (with --confidence-tiers)")
+	rootCmd.Flags().Float64Var(&reviewThresholdFlag, "review-threshold", tiers.DefaultReviewThreshold, "Minimum confidence for an operation to be queued for review instead of skipped (with --confidence-tiers)")
+	rootCmd.Flags().BoolVar(&mergeMetadataFlag, "merge-duplicate-metadata", false, "Name the kept copy of each duplicate group using the best filename and embedded metadata across all copies")
+	rootCmd.Flags().BoolVar(&nearDuplicatesFlag, "near-duplicates", false, "Also report PDFs that match except for a few extra pages (e.g. watermark or cover pages) for review; ignored with --skip-cloud-hash")
+	rootCmd.Flags().BoolVar(&portableFlag, "portable", false, "Keep the index, journal and config inside the library (<PATH>/.ebook-renamer) so they move with it")
+	rootCmd.Flags().BoolVar(&breakLockFlag, "break-lock", false, "Take over the library lock even if another run appears to hold it")
+	rootCmd.Flags().StringVar(&langFlag, "lang", "", "Language of JSON todo messages (zh, en); also adds stable message_id and params fields to each todo item")
+	rootCmd.Flags().BoolVar(&reasonCodesFlag, "reason-codes", false, "Add stable reason codes (e.g. NOISE_STRIPPED, DUP_CONTENT_HASH) to every rename, delete and todo item in the JSON output")
+	rootCmd.Flags().StringVar(&copyFlag, "copy", "", "At the end of the run, copy the short run summary or todo.md to the system clipboard (summary, todo)")
+	rootCmd.Flags().BoolVar(&oneLineFlag, "one-line", false, "Print a one-line run summary suitable for pasting into notes")
+	rootCmd.Flags().BoolVar(&noUpdateCheckFlag, "no-update-check", false, "Don't check GitHub for a newer release (also disabled by $EBOOK_RENAMER_NO_UPDATE_CHECK)")
 }
 
 func Execute() error {
@@ -103,6 +143,37 @@ func runEbookRenamer(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("invalid max-depth: %w", err)
 	}
 
+	// Resolve where the library state lives and apply its config to flags not given explicitly
+	layout, err := state.Resolve(absPath, portableFlag)
+	if err != nil {
+		return fmt.Errorf("state resolution failed: %w", err)
+	}
+	if err := applyLibraryConfig(cmd, layout); err != nil {
+		return err
+	}
+
+	var lang i18n.Lang
+	if langFlag != "" {
+		if lang, err = i18n.Parse(langFlag); err != nil {
+			return err
+		}
+	}
+
+	if copyFlag != "" && copyFlag != copySummary && copyFlag != copyTodo {
+		return fmt.Errorf("invalid --copy value %q (use %s or %s)", copyFlag, copySummary, copyTodo)
+	}
+
+	if sampleFlag < 0 {
+		return fmt.Errorf("invalid sample size: %d", sampleFlag)
+	}
+
+	if confidenceTiersFlag {
+		thresholds := tiers.Thresholds{Auto: autoThresholdFlag, Review: reviewThresholdFlag}
+		if err := thresholds.Validate(); err != nil {
+			return fmt.Errorf("invalid confidence thresholds: %w", err)
+		}
+	}
+
 	// Handle --no-recursive by setting max_depth to 1
 	effectiveMaxDepth := maxDepth
 	if noRecursiveFlag {
@@ -148,12 +219,40 @@ func runEbookRenamer(cmd *cobra.Command, args []string) error {
 		AutoCleanup:     autoCleanupFlag,
 		Json:            jsonFlag,
 		SkipCloudHash:   skipCloudHashFlag,
+		Sample:          sampleFlag,
+		ConfidenceTiers: confidenceTiersFlag,
+		AutoThreshold:   autoThresholdFlag,
+		ReviewThreshold: reviewThresholdFlag,
+		MergeMetadata:   mergeMetadataFlag,
+		NearDuplicates:  nearDuplicatesFlag,
+		Lang:            string(lang),
+		ReasonCodes:     reasonCodesFlag,
+		Copy:            copyFlag,
+		OneLine:         oneLineFlag,
+		Portable:        layout.Portable,
+		BreakLock:       breakLockFlag,
 	}
 
 	log.Printf("Starting ebook renamer with config: %+v", config)
 
-	if config.Json {
-		return processFiles(config)
+	if config.Sample > 0 && config.DryRun {
+		fmt.Fprintf(os.Stderr, "⚠️  Warning: --sample has no effect in dry-run mode.\n")
+	}
+
+	// Look for a newer release while the library is processed
+	versionNotice := startVersionCheck(noUpdateCheckFlag)
+
+	if config.NearDuplicates && config.SkipCloudHash {
+		fmt.Fprintf(os.Stderr, "⚠️  Warning: --near-duplicates reads file contents and is ignored with --skip-cloud-hash.\n")
+	}
+
+	// The sample review needs the terminal; tiering, near-duplicates and summary sharing are not wired into the TUI
+	if config.Json || (config.Sample > 0 && !config.DryRun) || config.ConfidenceTiers || config.NearDuplicates || config.Copy != "" || config.OneLine {
+		if err := processFiles(config); err != nil {
+			return err
+		}
+		printVersionNotice(versionNotice, config.Json)
+		return nil
 	}
 
 	// Run TUI
@@ -161,6 +260,33 @@ func runEbookRenamer(cmd *cobra.Command, args []string) error {
 	if _, err := p.Run(); err != nil {
 		return fmt.Errorf("error running program: %w", err)
 	}
+	printVersionNotice(versionNotice, false)
+	return nil
+}
+
+// applyLibraryConfig fills in flags from the library config unless they were set on the command line
+func applyLibraryConfig(cmd *cobra.Command, layout *state.Layout) error {
+	libConfig, err := layout.LoadConfig()
+	if err != nil {
+		return err
+	}
+
+	flags := cmd.Flags()
+	if libConfig.TodoFile != "" && !flags.Changed("todo-file") {
+		todoFileFlag = layout.Abs(libConfig.TodoFile)
+	}
+	if libConfig.NoDelete != nil && !flags.Changed("no-delete") {
+		noDeleteFlag = *libConfig.NoDelete
+	}
+	if libConfig.SkipCloudHash != nil && !flags.Changed("skip-cloud-hash") {
+		skipCloudHashFlag = *libConfig.SkipCloudHash
+	}
+	if libConfig.AutoThreshold != nil && !flags.Changed("auto-threshold") {
+		autoThresholdFlag = *libConfig.AutoThreshold
+	}
+	if libConfig.ReviewThreshold != nil && !flags.Changed("review-threshold") {
+		reviewThresholdFlag = *libConfig.ReviewThreshold
+	}
 	return nil
 }
 
@@ -196,7 +322,7 @@ func processFiles(config *types.Config) error {
 	todoFilePath := determineTodoFile(config.Path, config.TodoFile)
 
 	// Create todo list
-	todoList, err := todo.New(todoFilePath, config.Path)
+	todoList, err := todo.New(todoFilePath, config.Path, i18n.Lang(config.Lang))
 	if err != nil {
 		return fmt.Errorf("todo list creation failed: %w", err)
 	}
@@ -231,27 +357,28 @@ func processFiles(config *types.Config) error {
 
 	// Track files to delete and todo items
 	var filesToDelete []string
+	deleteReasons := make(map[string]types.ReasonCode)
 	var todoItems []types.TodoItem
 	cleanupResult := &types.CleanupResult{
 		DeletedIncomplete: []string{},
 		DeletedCorrupted:  []string{},
 		DeletedSmall:      []string{},
+		DeletedDuplicates: []string{},
 		FailedDeletions:   []types.FailedDeletion{},
+		Conflicts:         []types.Conflict{},
 	}
 
 	// Process incomplete downloads
 	for _, fileInfo := range incompleteDownloads {
 		if shouldCleanup {
 			filesToDelete = append(filesToDelete, fileInfo.OriginalPath)
+			deleteReasons[fileInfo.OriginalPath] = types.ReasonIncompleteSuffix
 			cleanupResult.DeletedIncomplete = append(cleanupResult.DeletedIncomplete, fileInfo.OriginalPath)
 			todoList.RemoveFileFromTodo(fileInfo.OriginalName)
 		} else {
 			todoList.AddFailedDownload(fileInfo)
-			todoItems = append(todoItems, types.TodoItem{
-				Category: "failed_download",
-				File:     fileInfo.OriginalName,
-				Message:  fmt.Sprintf("重新下载: %s (未完成下载)", fileInfo.OriginalName),
-			})
+			todoItems = append(todoItems, newTodoItem(config, "failed_download", fileInfo.OriginalName,
+				i18n.MsgRedownloadIncomplete, i18n.Params{"file": fileInfo.OriginalName}))
 		}
 	}
 
@@ -259,15 +386,13 @@ func processFiles(config *types.Config) error {
 	for _, fileInfo := range corruptedFiles {
 		if shouldCleanup {
 			filesToDelete = append(filesToDelete, fileInfo.OriginalPath)
+			deleteReasons[fileInfo.OriginalPath] = types.ReasonCorruptHeader
 			cleanupResult.DeletedCorrupted = append(cleanupResult.DeletedCorrupted, fileInfo.OriginalPath)
 			todoList.RemoveFileFromTodo(fileInfo.OriginalName)
 		} else {
 			todoList.AddFileIssue(fileInfo, types.FileIssueCorruptedPdf)
-			todoItems = append(todoItems, types.TodoItem{
-				Category: "corrupted",
-				File:     fileInfo.OriginalName,
-				Message:  fmt.Sprintf("重新下载: %s (PDF文件损坏)", fileInfo.OriginalName),
-			})
+			todoItems = append(todoItems, newTodoItem(config, "corrupted", fileInfo.OriginalName,
+				i18n.MsgRedownloadCorrupted, i18n.Params{"file": fileInfo.OriginalName}))
 		}
 	}
 
@@ -275,23 +400,18 @@ func processFiles(config *types.Config) error {
 	for _, fileInfo := range smallFiles {
 		if config.DeleteSmall {
 			filesToDelete = append(filesToDelete, fileInfo.OriginalPath)
+			deleteReasons[fileInfo.OriginalPath] = types.ReasonTooSmall
 			cleanupResult.DeletedSmall = append(cleanupResult.DeletedSmall, fileInfo.OriginalPath)
 			todoList.RemoveFileFromTodo(fileInfo.OriginalName)
 		} else if config.AutoCleanup {
 			// Auto-cleanup mode: add to todo for manual review (might be valid small ebook)
 			todoList.AddFailedDownload(fileInfo)
-			todoItems = append(todoItems, types.TodoItem{
-				Category: "too_small",
-				File:     fileInfo.OriginalName,
-				Message:  fmt.Sprintf("检查文件: %s (文件过小 %d 字节，可能需要重新下载)", fileInfo.OriginalName, fileInfo.Size),
-			})
+			todoItems = append(todoItems, newTodoItem(config, "too_small", fileInfo.OriginalName,
+				i18n.MsgCheckSmallFile, i18n.Params{"file": fileInfo.OriginalName, "size": strconv.FormatUint(fileInfo.Size, 10)}))
 		} else {
 			todoList.AddFailedDownload(fileInfo)
-			todoItems = append(todoItems, types.TodoItem{
-				Category: "too_small",
-				File:     fileInfo.OriginalName,
-				Message:  fmt.Sprintf("检查并重新下载: %s (文件过小，仅 %d 字节)", fileInfo.OriginalName, fileInfo.Size),
-			})
+			todoItems = append(todoItems, newTodoItem(config, "too_small", fileInfo.OriginalName,
+				i18n.MsgRedownloadSmallFile, i18n.Params{"file": fileInfo.OriginalName, "size": strconv.FormatUint(fileInfo.Size, 10)}))
 		}
 	}
 
@@ -328,6 +448,35 @@ func processFiles(config *types.Config) error {
 	}
 	log.Printf("Detected %d duplicate groups", len(duplicateGroups))
 
+	// Give each kept copy the richest name found across its group
+	if config.MergeMetadata && !config.NoDelete {
+		var merged int
+		duplicateGroups, cleanFiles, merged = duplicates.MergeGroupMetadata(duplicateGroups, normalized, cleanFiles, !config.SkipCloudHash)
+		log.Printf("Merged metadata for %d duplicate groups", merged)
+	}
+
+	// Near-duplicates are only reported, since the extra pages may be wanted
+	var nearDuplicates []types.NearDuplicate
+	if config.NearDuplicates && !config.SkipCloudHash {
+		nearDuplicates = duplicates.DetectNearDuplicates(cleanFiles)
+		for _, nd := range nearDuplicates {
+			todoList.AddNearDuplicate(nd)
+			todoItems = append(todoItems, newTodoItem(config, "near_duplicate", filepath.Base(nd.Duplicate),
+				i18n.MsgReviewNearDuplicate, todo.NearDuplicateParams(nd)))
+		}
+	}
+
+	// Only the auto tier stays in the plan; review items go to todo.md
+	if config.ConfidenceTiers {
+		var counts tiers.Counts
+		duplicateGroups, todoItems, counts = applyTiers(normalized, cleanFiles, duplicateGroups, todoItems, todoList, config)
+		filesToDelete, todoItems = applyCleanupTiers(normalized, filesToDelete, deleteReasons, cleanupResult, todoItems, todoList, config, &counts)
+		log.Printf("Confidence tiers: %d auto, %d review, %d skip", counts.Auto, counts.Review, counts.Skip)
+		if !config.Json {
+			fmt.Printf("\n🎯 置信度分级: 自动执行 %d 个，待审核 %d 个，跳过 %d 个\n", counts.Auto, counts.Review, counts.Skip)
+		}
+	}
+
 	// Sort todo items by category, then file for deterministic output (matching Rust)
 	sort.Slice(todoItems, func(i, j int) bool {
 		if todoItems[i].Category != todoItems[j].Category {
@@ -337,6 +486,7 @@ func processFiles(config *types.Config) error {
 	})
 
 	// Output results
+	var runSummary *summary.Summary
 	if config.DryRun {
 		if config.Json {
 			// JSON output
@@ -344,6 +494,10 @@ func processFiles(config *types.Config) error {
 			if err != nil {
 				return fmt.Errorf("JSON output generation failed: %w", err)
 			}
+			jsonoutput.AddNearDuplicates(output, nearDuplicates, config.Path)
+			if config.ReasonCodes {
+				jsonoutput.AddReasonCodes(output, cleanFiles, deleteReasons, config.SkipCloudHash, config.Path)
+			}
 			jsonStr, err := jsonoutput.ToJSON(output)
 			if err != nil {
 				return fmt.Errorf("JSON serialization failed: %w", err)
@@ -362,9 +516,29 @@ func processFiles(config *types.Config) error {
 		if !config.Json {
 			fmt.Println("\n✓ todo.md written (dry-run mode)")
 		}
+
+		runSummary = plannedSummary(config, cleanFiles, duplicateGroups, filesToDelete)
 	} else {
+		// Let the user approve a random sample before touching anything
+		if config.Sample > 0 {
+			accepted, err := confirmSample(cleanFiles, duplicateGroups, filesToDelete, config)
+			if err != nil {
+				return fmt.Errorf("sample review failed: %w", err)
+			}
+			if !accepted {
+				if err := todoList.Write(); err != nil {
+					return fmt.Errorf("todo write failed: %w", err)
+				}
+				if !config.Json {
+					fmt.Println("\n✗ Sample rejected, no changes applied (todo.md written)")
+				}
+				shareSummary(config, &summary.Summary{Time: time.Now(), Library: config.Path, Rejected: true, Todo: todoList.GetItems()}, todoFilePath)
+				return nil
+			}
+		}
+
 		// Execute operations
-		cleanupResult, err = executeOperations(cleanFiles, duplicateGroups, filesToDelete, todoList, config, cleanupResult)
+		cleanupResult, err = executeOperations(normalized, cleanFiles, duplicateGroups, filesToDelete, todoList, config, cleanupResult)
 		if err != nil {
 			return fmt.Errorf("execution failed: %w", err)
 		}
@@ -373,15 +547,252 @@ func processFiles(config *types.Config) error {
 		if !config.Json {
 			printCleanupSummary(cleanupResult)
 		}
+
+		runSummary = executedSummary(config, cleanFiles, cleanupResult)
 	}
 
 	if !config.Json {
 		fmt.Println("\n✓ Operation completed successfully!")
 	}
 
+	runSummary.Todo = todoList.GetItems()
+	shareSummary(config, runSummary, todoFilePath)
+
 	return nil
 }
 
+// applyTiers removes review and skip tier operations from the plan.
+// Review tier operations are added to the todo list, skip tier operations are only logged.
+func applyTiers(normalized []*types.FileInfo, cleanFiles []*types.FileInfo, duplicateGroups [][]string, todoItems []types.TodoItem, todoList *todo.TodoList, config *types.Config) ([][]string, []types.TodoItem, tiers.Counts) {
+	thresholds := tiers.Thresholds{Auto: config.AutoThreshold, Review: config.ReviewThreshold}
+	var counts tiers.Counts
+
+	for _, fileInfo := range cleanFiles {
+		if fileInfo.NewName == nil || *fileInfo.NewName == fileInfo.OriginalName {
+			continue
+		}
+
+		tier := thresholds.Classify(fileInfo.Confidence)
+		counts.Add(tier)
+		switch tier {
+		case tiers.TierReview:
+			todoList.AddRenameReview(fileInfo)
+			todoItems = append(todoItems, newTodoItem(config, "needs_review", fileInfo.OriginalName,
+				i18n.MsgReviewRename, todo.RenameReviewParams(fileInfo)))
+			fileInfo.NewName = nil
+		case tiers.TierSkip:
+			log.Printf("Skipped low-confidence rename (%.2f): %s -> %s", fileInfo.Confidence, fileInfo.OriginalName, *fileInfo.NewName)
+			fileInfo.NewName = nil
+		}
+	}
+
+	byPath := make(map[string]*types.FileInfo, len(normalized))
+	for _, fileInfo := range normalized {
+		byPath[fileInfo.OriginalPath] = fileInfo
+	}
+
+	var autoGroups [][]string
+	for _, group := range duplicateGroups {
+		var members []*types.FileInfo
+		for _, path := range group {
+			if fileInfo, ok := byPath[path]; ok {
+				members = append(members, fileInfo)
+			}
+		}
+		confidence := duplicates.GroupConfidence(members, config.SkipCloudHash)
+		tier := thresholds.Classify(confidence)
+		counts.Add(tier)
+		switch tier {
+		case tiers.TierAuto:
+			autoGroups = append(autoGroups, group)
+		case tiers.TierReview:
+			todoList.AddDuplicateReview(group, confidence)
+			todoItems = append(todoItems, newTodoItem(config, "needs_review", filepath.Base(group[0]),
+				i18n.MsgReviewDuplicates, todo.DuplicateReviewParams(group, confidence)))
+		case tiers.TierSkip:
+			log.Printf("Skipped low-confidence duplicate group (%.2f): %s", confidence, group[0])
+		}
+	}
+
+	return autoGroups, todoItems, counts
+}
+
+// applyCleanupTiers removes review and skip tier deletions of problem files from the plan,
+// the same way applyTiers does for renames and duplicate groups
+func applyCleanupTiers(normalized []*types.FileInfo, filesToDelete []string, deleteReasons map[string]types.ReasonCode, cleanupResult *types.CleanupResult, todoItems []types.TodoItem, todoList *todo.TodoList, config *types.Config, counts *tiers.Counts) ([]string, []types.TodoItem) {
+	thresholds := tiers.Thresholds{Auto: config.AutoThreshold, Review: config.ReviewThreshold}
+	byPath := make(map[string]*types.FileInfo, len(normalized))
+	for _, fileInfo := range normalized {
+		byPath[fileInfo.OriginalPath] = fileInfo
+	}
+
+	var autoDeletes []string
+	for _, path := range filesToDelete {
+		confidence := cleanupConfidence(byPath[path], deleteReasons[path])
+		tier := thresholds.Classify(confidence)
+		counts.Add(tier)
+		if tier == tiers.TierAuto {
+			autoDeletes = append(autoDeletes, path)
+			continue
+		}
+
+		cleanupResult.DeletedIncomplete = removeFromSlice(cleanupResult.DeletedIncomplete, path)
+		cleanupResult.DeletedCorrupted = removeFromSlice(cleanupResult.DeletedCorrupted, path)
+		cleanupResult.DeletedSmall = removeFromSlice(cleanupResult.DeletedSmall, path)
+		if tier == tiers.TierReview {
+			todoList.AddCleanupReview(path, confidence)
+			todoItems = append(todoItems, newTodoItem(config, "needs_review", filepath.Base(path),
+				i18n.MsgReviewCleanup, todo.CleanupReviewParams(path, confidence)))
+		} else {
+			log.Printf("Skipped low-confidence deletion (%.2f): %s", confidence, path)
+		}
+	}
+	return autoDeletes, todoItems
+}
+
+// cleanupConfidence scores how certain it is that a problem file is worthless
+func cleanupConfidence(fileInfo *types.FileInfo, reason types.ReasonCode) float64 {
+	switch reason {
+	case types.ReasonIncompleteSuffix:
+		// The browser never finished the download
+		return 0.95
+	case types.ReasonCorruptHeader:
+		// Readers accept a header anywhere in the first kilobyte, so such a file may still open
+		if fileInfo != nil && hasLatePDFHeader(fileInfo.OriginalPath) {
+			return 0.5
+		}
+		return 0.9
+	case types.ReasonTooSmall:
+		if fileInfo != nil && fileInfo.Size == 0 {
+			return 1
+		}
+		// Small, but possibly a short valid document
+		return 0.7
+	}
+	return 0
+}
+
+// hasLatePDFHeader reports whether the PDF header appears after the start of the first kilobyte
+func hasLatePDFHeader(filePath string) bool {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+	head := make([]byte, 1024)
+	n, _ := io.ReadFull(file, head)
+	return bytes.Index(head[:n], []byte("%PDF-")) > 0
+}
+
+// Values of --copy
+const (
+	copySummary = "summary"
+	copyTodo    = "todo"
+)
+
+// plannedSummary counts what a dry run would do
+func plannedSummary(config *types.Config, cleanFiles []*types.FileInfo, duplicateGroups [][]string, filesToDelete []string) *summary.Summary {
+	s := &summary.Summary{Time: time.Now(), Library: config.Path, DryRun: true, Cleaned: len(filesToDelete)}
+	for _, fileInfo := range cleanFiles {
+		if fileInfo.NewName != nil && *fileInfo.NewName != fileInfo.OriginalName {
+			s.Renamed++
+		}
+	}
+	if !config.NoDelete {
+		for _, group := range duplicateGroups {
+			if len(group) > 1 {
+				s.Duplicates += len(group) - 1
+			}
+		}
+	}
+	return s
+}
+
+// executedSummary counts what a run actually did
+func executedSummary(config *types.Config, cleanFiles []*types.FileInfo, result *types.CleanupResult) *summary.Summary {
+	s := &summary.Summary{
+		Time:       time.Now(),
+		Library:    config.Path,
+		Duplicates: len(result.DeletedDuplicates),
+		Cleaned:    len(result.DeletedIncomplete) + len(result.DeletedCorrupted) + len(result.DeletedSmall),
+		Conflicts:  len(result.Conflicts),
+	}
+	for _, fileInfo := range cleanFiles {
+		if fileInfo.NewName != nil && fileInfo.NewPath != fileInfo.OriginalPath {
+			s.Renamed++
+		}
+	}
+	return s
+}
+
+// shareSummary prints the one-line summary and copies the summary or todo.md to the clipboard as requested.
+// Clipboard failures only warn, since the run itself has succeeded.
+func shareSummary(config *types.Config, s *summary.Summary, todoFilePath string) {
+	out := os.Stdout
+	if config.Json {
+		// Keep stdout valid JSON
+		out = os.Stderr
+	}
+
+	if config.OneLine {
+		fmt.Fprintf(out, "\n%s\n", s.OneLine())
+	}
+	if config.Copy == "" {
+		return
+	}
+
+	text, label := s.Text(), "summary"
+	if config.Copy == copyTodo {
+		data, err := os.ReadFile(todoFilePath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "⚠️  Warning: could not read %s: %v\n", todoFilePath, err)
+			return
+		}
+		text, label = string(data), "todo.md"
+	}
+	if err := clipboard.Write(text); err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  Warning: could not copy %s to the clipboard: %v\n", label, err)
+		return
+	}
+	fmt.Fprintf(out, "📋 Copied %s to the clipboard\n", label)
+}
+
+// newTodoItem builds a todo item for the JSON output. With --lang the message is shown in that
+// language and its ID and parameters are included, so consumers need not parse display text.
+func newTodoItem(config *types.Config, category, file string, id i18n.MessageID, params i18n.Params) types.TodoItem {
+	item := types.TodoItem{Category: category, File: file}
+	if config.Lang == "" {
+		item.Message = i18n.Format(i18n.Default, id, params)
+		return item
+	}
+	item.Message = i18n.Format(i18n.Lang(config.Lang), id, params)
+	item.MessageID = string(id)
+	item.Params = params
+	return item
+}
+
+// confirmSample asks the user to approve a random sample of the planned operations
+func confirmSample(cleanFiles []*types.FileInfo, duplicateGroups [][]string, filesToDelete []string, config *types.Config) (bool, error) {
+	ops := review.PlannedOperations(cleanFiles, duplicateGroups, filesToDelete, config.NoDelete)
+	if len(ops) == 0 {
+		return true, nil
+	}
+
+	// Keep stdout clean for JSON consumers
+	out := os.Stdout
+	if config.Json {
+		out = os.Stderr
+	}
+
+	sample := review.Sample(ops, config.Sample, rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64())))
+	accepted, err := review.Confirm(os.Stdin, out, sample, len(ops))
+	if err != nil {
+		return false, err
+	}
+	log.Printf("Sample review of %d/%d operations accepted: %v", len(sample), len(ops), accepted)
+	return accepted, nil
+}
+
 // validatePDFHeader validates that a PDF file has the correct header
 func validatePDFHeader(filePath string) error {
 	file, err := os.Open(filePath)
@@ -453,7 +864,7 @@ func printIssueSummary(incomplete, corrupted, small []*types.FileInfo) {
 func printCleanupSummary(result *types.CleanupResult) {
 	totalDeleted := len(result.DeletedIncomplete) + len(result.DeletedCorrupted) + len(result.DeletedSmall)
 
-	if totalDeleted == 0 && len(result.FailedDeletions) == 0 {
+	if totalDeleted == 0 && len(result.FailedDeletions) == 0 && len(result.Conflicts) == 0 {
 		return
 	}
 
@@ -482,6 +893,16 @@ func printCleanupSummary(result *types.CleanupResult) {
 		}
 	}
 
+	if len(result.Conflicts) > 0 {
+		fmt.Printf("  ⚠️  冲突跳过: %d 个 (文件在扫描后被修改)\n", len(result.Conflicts))
+		for i, c := range result.Conflicts {
+			if i >= 3 {
+				break
+			}
+			fmt.Printf("     • %s: %s\n", filepath.Base(c.Path), c.Reason)
+		}
+	}
+
 	fmt.Println("----------------------------------------")
 }
 
@@ -534,27 +955,56 @@ func printHumanOutput(cleanFiles []*types.FileInfo, duplicateGroups [][]string,
 	}
 }
 
-func executeOperations(cleanFiles []*types.FileInfo, duplicateGroups [][]string, filesToDelete []string, todoList *todo.TodoList, config *types.Config, cleanupResult *types.CleanupResult) (*types.CleanupResult, error) {
-	// Execute renames
-	for _, fileInfo := range cleanFiles {
-		if fileInfo.NewName != nil {
-			if err := os.Rename(fileInfo.OriginalPath, fileInfo.NewPath); err != nil {
-				return cleanupResult, fmt.Errorf("rename failed: %w", err)
-			}
-			log.Printf("Renamed: %s -> %s", fileInfo.OriginalName, *fileInfo.NewName)
-		}
+func executeOperations(normalized []*types.FileInfo, cleanFiles []*types.FileInfo, duplicateGroups [][]string, filesToDelete []string, todoList *todo.TodoList, config *types.Config, cleanupResult *types.CleanupResult) (*types.CleanupResult, error) {
+	// Keep other users of a shared library out while we work
+	libLock, err := lock.Acquire(config.Path, config.BreakLock)
+	if err != nil {
+		return cleanupResult, fmt.Errorf("%w (use --break-lock if it is stale)", err)
+	}
+	defer libLock.ReleaseOrLog()
+
+	layout, err := state.Resolve(config.Path, config.Portable)
+	if err != nil {
+		return cleanupResult, fmt.Errorf("state resolution failed: %w", err)
+	}
+	if err := layout.Ensure(); err != nil {
+		return cleanupResult, fmt.Errorf("failed to create state directory: %w", err)
+	}
+	user, host := lock.Identity()
+	j, err := journal.Open(layout.JournalPath(), layout.Rel, user, host)
+	if err != nil {
+		return cleanupResult, err
+	}
+	defer j.Close()
+
+	byPath := make(map[string]*types.FileInfo, len(normalized))
+	for _, fileInfo := range normalized {
+		byPath[fileInfo.OriginalPath] = fileInfo
 	}
 
 	// Delete duplicates
 	if !config.NoDelete {
 		for _, group := range duplicateGroups {
 			if len(group) > 1 {
+				// Never delete copies unless the kept file is still there
+				if err := lock.CheckKept(byPath[group[0]]); err != nil {
+					cleanupResult.Conflicts = append(cleanupResult.Conflicts, conflictFor(group[0], err))
+					continue
+				}
 				for i, path := range group {
 					if i > 0 {
+						if fileInfo, ok := byPath[path]; ok {
+							if err := lock.CheckUnchanged(fileInfo); err != nil {
+								cleanupResult.Conflicts = append(cleanupResult.Conflicts, conflictFor(path, err))
+								continue
+							}
+						}
 						if err := os.Remove(path); err != nil {
 							log.Printf("Failed to delete duplicate: %s: %v", path, err)
 						} else {
 							log.Printf("Deleted duplicate: %s", path)
+							cleanupResult.DeletedDuplicates = append(cleanupResult.DeletedDuplicates, path)
+							j.RecordOrLog(journal.OpDelete, path, group[0], "duplicate")
 						}
 					}
 				}
@@ -563,8 +1013,18 @@ func executeOperations(cleanFiles []*types.FileInfo, duplicateGroups [][]string,
 	}
 
 	// Delete problematic files (incomplete downloads, corrupted, small)
+	cleanedUp := make(map[string]bool, len(filesToDelete))
 	if len(filesToDelete) > 0 {
 		for _, path := range filesToDelete {
+			if fileInfo, ok := byPath[path]; ok {
+				if err := lock.CheckUnchanged(fileInfo); err != nil {
+					cleanupResult.Conflicts = append(cleanupResult.Conflicts, conflictFor(path, err))
+					cleanupResult.DeletedIncomplete = removeFromSlice(cleanupResult.DeletedIncomplete, path)
+					cleanupResult.DeletedCorrupted = removeFromSlice(cleanupResult.DeletedCorrupted, path)
+					cleanupResult.DeletedSmall = removeFromSlice(cleanupResult.DeletedSmall, path)
+					continue
+				}
+			}
 			if err := os.Remove(path); err != nil {
 				log.Printf("Failed to delete file: %s: %v", path, err)
 				cleanupResult.FailedDeletions = append(cleanupResult.FailedDeletions, types.FailedDeletion{
@@ -577,6 +1037,30 @@ func executeOperations(cleanFiles []*types.FileInfo, duplicateGroups [][]string,
 				cleanupResult.DeletedSmall = removeFromSlice(cleanupResult.DeletedSmall, path)
 			} else {
 				log.Printf("Deleted problematic file: %s", path)
+				cleanedUp[path] = true
+				j.RecordOrLog(journal.OpDelete, path, "", "cleanup")
+			}
+		}
+	}
+
+	// Execute renames after deletions, so a kept copy can take over a deleted duplicate's name
+	for _, fileInfo := range cleanFiles {
+		if fileInfo.NewName != nil && cleanedUp[fileInfo.OriginalPath] {
+			// Corrupted files are planned for renaming too, but are gone now
+			fileInfo.NewName = nil
+		}
+		if fileInfo.NewName != nil {
+			if err := lock.CheckRename(fileInfo); err != nil {
+				cleanupResult.Conflicts = append(cleanupResult.Conflicts, conflictFor(fileInfo.OriginalPath, err))
+				fileInfo.NewName = nil
+				continue
+			}
+			if err := os.Rename(fileInfo.OriginalPath, fileInfo.NewPath); err != nil {
+				return cleanupResult, fmt.Errorf("rename failed: %w", err)
+			}
+			log.Printf("Renamed: %s -> %s", fileInfo.OriginalName, *fileInfo.NewName)
+			if fileInfo.NewPath != fileInfo.OriginalPath {
+				j.RecordOrLog(journal.OpRename, fileInfo.OriginalPath, fileInfo.NewPath, "normalized")
 			}
 		}
 	}
@@ -587,9 +1071,41 @@ func executeOperations(cleanFiles []*types.FileInfo, duplicateGroups [][]string,
 	}
 	log.Printf("Wrote todo.md")
 
+	// Refresh the library index
+	if err := index.Build(remainingFiles(normalized, cleanFiles, duplicateGroups), layout.Rel).Save(layout.IndexPath()); err != nil {
+		return cleanupResult, fmt.Errorf("index write failed: %w", err)
+	}
+	log.Printf("Wrote index: %s", layout.IndexPath())
+
 	return cleanupResult, nil
 }
 
+func conflictFor(path string, err error) types.Conflict {
+	log.Printf("Conflict, skipping %s: %v", path, err)
+	return types.Conflict{Path: path, Reason: err.Error()}
+}
+
+// remainingFiles lists the files that may still exist after execution:
+// the clean files plus duplicate copies, which were never renamed
+func remainingFiles(normalized []*types.FileInfo, cleanFiles []*types.FileInfo, duplicateGroups [][]string) []*types.FileInfo {
+	byPath := make(map[string]*types.FileInfo, len(normalized))
+	for _, fileInfo := range normalized {
+		byPath[fileInfo.OriginalPath] = fileInfo
+	}
+
+	files := append([]*types.FileInfo{}, cleanFiles...)
+	for _, group := range duplicateGroups {
+		for _, path := range group[1:] {
+			if fileInfo, ok := byPath[path]; ok {
+				unrenamed := *fileInfo
+				unrenamed.NewName = nil
+				files = append(files, &unrenamed)
+			}
+		}
+	}
+	return files
+}
+
 func removeFromSlice(slice []string, item string) []string {
 	result := make([]string, 0, len(slice))
 	for _, s := range slice {
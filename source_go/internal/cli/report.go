@@ -0,0 +1,62 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/ebook-renamer/go/internal/index"
+	"github.com/ebook-renamer/go/internal/report"
+	"github.com/ebook-renamer/go/internal/state"
+	"github.com/spf13/cobra"
+)
+
+var reportJsonFlag bool
+
+var reportCmd = &cobra.Command{
+	Use:   "report [PATH]",
+	Short: "Show series with missing volumes and co-author clusters from the library index",
+	Long: `Show series with missing volumes and co-author clusters from the library index.
+
+The index is written by every non-dry run, so run ebook-renamer on the library first.`,
+	Args:         cobra.MaximumNArgs(1),
+	SilenceUsage: true,
+	RunE:         runReport,
+}
+
+func init() {
+	reportCmd.Flags().BoolVar(&reportJsonFlag, "json", false, "Output the report in JSON format")
+	rootCmd.AddCommand(reportCmd)
+}
+
+func runReport(cmd *cobra.Command, args []string) error {
+	root, err := libraryArg(args)
+	if err != nil {
+		return err
+	}
+	layout, err := state.Resolve(root, false)
+	if err != nil {
+		return fmt.Errorf("state resolution failed: %w", err)
+	}
+
+	idx, err := index.Load(layout.IndexPath())
+	if os.IsNotExist(err) {
+		return fmt.Errorf("no index for %s yet (run ebook-renamer on it first)", root)
+	} else if err != nil {
+		return err
+	}
+
+	r := report.Build(idx)
+	if reportJsonFlag {
+		data, err := json.MarshalIndent(r, "", "  ")
+		if err != nil {
+			return fmt.Errorf("JSON serialization failed: %w", err)
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	fmt.Printf("Library: %s (%d books, index updated %s)\n\n", root, r.Books, idx.UpdatedAt.Local().Format("2006-01-02 15:04"))
+	r.WriteText(os.Stdout)
+	return nil
+}
@@ -0,0 +1,129 @@
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/x/term"
+	"github.com/ebook-renamer/go/internal/secrets"
+	"github.com/spf13/cobra"
+)
+
+var secretsCmd = &cobra.Command{
+	Use:   "secrets",
+	Short: "Manage stored provider tokens and API keys",
+	Long: `Manage provider tokens and API keys.
+
+Secrets are kept in the OS keyring when one is available, otherwise in an
+encrypted file in the user config directory. The file is protected by a
+passphrase taken from $EBOOK_RENAMER_SECRETS_PASSPHRASE or asked for
+interactively.`,
+}
+
+var secretsSetCmd = &cobra.Command{
+	Use:   "set KEY",
+	Short: "Store a secret read from the terminal or stdin",
+	Long: `Store a secret. The value is asked for without echo on a terminal, or read
+from the first line of stdin, so it never ends up in shell history.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := secrets.Open(promptPassphrase)
+		if err != nil {
+			return err
+		}
+
+		value, err := readSecretValue(args[0])
+		if err != nil {
+			return err
+		}
+		if value == "" {
+			return fmt.Errorf("empty value for %s", args[0])
+		}
+
+		if err := store.Set(args[0], value); err != nil {
+			return fmt.Errorf("failed to store %s: %w", args[0], err)
+		}
+		fmt.Fprintf(os.Stderr, "✓ Stored %s in %s\n", args[0], store.Name())
+		return nil
+	},
+}
+
+var secretsGetCmd = &cobra.Command{
+	Use:   "get KEY",
+	Short: "Print a stored secret",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := secrets.Open(promptPassphrase)
+		if err != nil {
+			return err
+		}
+		value, err := store.Get(args[0])
+		if errors.Is(err, secrets.ErrNotFound) {
+			return fmt.Errorf("no secret named %s", args[0])
+		} else if err != nil {
+			return err
+		}
+		fmt.Println(value)
+		return nil
+	},
+}
+
+var secretsRmCmd = &cobra.Command{
+	Use:   "rm KEY",
+	Short: "Remove a stored secret",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := secrets.Open(promptPassphrase)
+		if err != nil {
+			return err
+		}
+		if err := store.Delete(args[0]); errors.Is(err, secrets.ErrNotFound) {
+			return fmt.Errorf("no secret named %s", args[0])
+		} else if err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "✓ Removed %s from %s\n", args[0], store.Name())
+		return nil
+	},
+}
+
+func init() {
+	for _, c := range []*cobra.Command{secretsSetCmd, secretsGetCmd, secretsRmCmd} {
+		// Lookup failures are not usage errors
+		c.SilenceUsage = true
+		secretsCmd.AddCommand(c)
+	}
+	rootCmd.AddCommand(secretsCmd)
+}
+
+// promptPassphrase asks for the secrets file passphrase without echoing it
+func promptPassphrase() (string, error) {
+	if !term.IsTerminal(os.Stdin.Fd()) {
+		return "", fmt.Errorf("no terminal to ask for the passphrase (set %s)", secrets.PassphraseEnv)
+	}
+	fmt.Fprint(os.Stderr, "Secrets passphrase: ")
+	pass, err := term.ReadPassword(os.Stdin.Fd())
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", fmt.Errorf("failed to read passphrase: %w", err)
+	}
+	return string(pass), nil
+}
+
+// readSecretValue reads a value without echo from a terminal, or the first line of piped input
+func readSecretValue(key string) (string, error) {
+	if term.IsTerminal(os.Stdin.Fd()) {
+		fmt.Fprintf(os.Stderr, "Value for %s: ", key)
+		value, err := term.ReadPassword(os.Stdin.Fd())
+		fmt.Fprintln(os.Stderr)
+		return string(value), err
+	}
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return "", fmt.Errorf("failed to read value: %w", err)
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
@@ -0,0 +1,153 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ebook-renamer/go/internal/lock"
+	"github.com/ebook-renamer/go/internal/snapshot"
+	"github.com/ebook-renamer/go/internal/state"
+	"github.com/spf13/cobra"
+)
+
+var (
+	exportOutputFlag   string
+	importForceFlag    bool
+	importPortableFlag bool
+)
+
+var exportStateCmd = &cobra.Command{
+	Use:   "export-state [PATH]",
+	Short: "Bundle the library index, journal and config into one archive",
+	Long: `Bundle the library index, journal and config into one archive.
+
+Use import-state on the new machine to restore them. Paths in the state are
+relative to the library, so the library may live somewhere else there.`,
+	Args:         cobra.MaximumNArgs(1),
+	SilenceUsage: true,
+	RunE:         runExportState,
+}
+
+var importStateCmd = &cobra.Command{
+	Use:          "import-state ARCHIVE [PATH]",
+	Short:        "Restore library state from an export-state archive",
+	Args:         cobra.RangeArgs(1, 2),
+	SilenceUsage: true,
+	RunE:         runImportState,
+}
+
+func init() {
+	exportStateCmd.Flags().StringVarP(&exportOutputFlag, "output", "o", "", "Archive to write, or - for stdout (default: ebook-renamer-state-<date>.tar.gz)")
+	importStateCmd.Flags().BoolVar(&importForceFlag, "force", false, "Replace existing state files for the library")
+	importStateCmd.Flags().BoolVar(&importPortableFlag, "portable", false, "Restore into <PATH>/.ebook-renamer instead of the user config directory")
+	rootCmd.AddCommand(exportStateCmd, importStateCmd)
+}
+
+// libraryArg resolves the optional library path argument
+func libraryArg(args []string) (string, error) {
+	path := "."
+	if len(args) > 0 {
+		path = args[0]
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("invalid path: %w", err)
+	}
+	if stat, err := os.Stat(absPath); err != nil {
+		return "", fmt.Errorf("path does not exist: %w", err)
+	} else if !stat.IsDir() {
+		return "", fmt.Errorf("path is not a directory: %s", absPath)
+	}
+	return absPath, nil
+}
+
+func runExportState(cmd *cobra.Command, args []string) error {
+	root, err := libraryArg(args)
+	if err != nil {
+		return err
+	}
+	layout, err := state.Resolve(root, false)
+	if err != nil {
+		return fmt.Errorf("state resolution failed: %w", err)
+	}
+
+	// Hold the lock so a concurrent run can't leave a half-written journal in the archive
+	libLock, err := lock.Acquire(root, false)
+	if err != nil {
+		return err
+	}
+	defer libLock.ReleaseOrLog()
+
+	output := exportOutputFlag
+	if output == "" {
+		output = fmt.Sprintf("ebook-renamer-state-%s.tar.gz", time.Now().Format("2006-01-02"))
+	}
+
+	var w io.Writer = os.Stdout
+	var f *os.File
+	if output != "-" {
+		f, err = os.Create(output)
+		if err != nil {
+			return fmt.Errorf("failed to create archive: %w", err)
+		}
+		w = f
+	}
+
+	manifest, err := snapshot.Export(layout, w)
+	if f != nil {
+		// A failed flush on close means a truncated archive
+		if closeErr := f.Close(); err == nil && closeErr != nil {
+			err = closeErr
+		}
+		// Drop the partial archive, but never a device or pipe given as output
+		if info, statErr := os.Stat(output); err != nil && statErr == nil && info.Mode().IsRegular() {
+			os.Remove(output)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("export failed: %w", err)
+	}
+
+	if output != "-" {
+		fmt.Printf("✓ Exported %d state files to %s\n", len(manifest.Files), output)
+	}
+	return nil
+}
+
+func runImportState(cmd *cobra.Command, args []string) error {
+	root, err := libraryArg(args[1:])
+	if err != nil {
+		return err
+	}
+	layout, err := state.Resolve(root, importPortableFlag)
+	if err != nil {
+		return fmt.Errorf("state resolution failed: %w", err)
+	}
+
+	libLock, err := lock.Acquire(root, false)
+	if err != nil {
+		return err
+	}
+	defer libLock.ReleaseOrLog()
+
+	f, err := os.Open(args[0])
+	if err != nil {
+		return fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer f.Close()
+
+	manifest, err := snapshot.Import(layout, f, importForceFlag)
+	if errors.Is(err, snapshot.ErrExists) {
+		return fmt.Errorf("%w (use --force to replace it)", err)
+	} else if err != nil {
+		return fmt.Errorf("import failed: %w", err)
+	}
+
+	fmt.Printf("✓ Imported %d state files from %s (exported %s)\n", len(manifest.Files), manifest.LibraryRoot, manifest.CreatedAt.Local().Format("2006-01-02 15:04"))
+	fmt.Printf("  State directory: %s\n", layout.Dir)
+	return nil
+}
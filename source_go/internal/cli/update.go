@@ -0,0 +1,127 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ebook-renamer/go/internal/update"
+	"github.com/spf13/cobra"
+)
+
+// Version is set at build time with -ldflags "-X github.com/ebook-renamer/go/internal/cli.Version=v1.2.3"
+var Version = "dev"
+
+// How long a run waits at the end for an unfinished version check
+const versionNoticeWait = 300 * time.Millisecond
+
+var (
+	updateCheckOnlyFlag bool
+	updateForceFlag     bool
+)
+
+var selfUpdateCmd = &cobra.Command{
+	Use:   "self-update",
+	Short: "Update ebook-renamer to the latest GitHub release",
+	Long: `Download the latest release for this platform from GitHub, check it against the release's checksums.txt and replace the running executable.
+
+The checksums are published in the same release as the binary, so they only catch a corrupted or truncated download. They are an integrity check, not a signature: they do not prove who built the release.`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE:         runSelfUpdate,
+}
+
+func init() {
+	selfUpdateCmd.Flags().BoolVar(&updateCheckOnlyFlag, "check", false, "Only report whether a newer release exists")
+	selfUpdateCmd.Flags().BoolVar(&updateForceFlag, "force", false, "Install the latest release even if it is not newer (e.g. over a development build)")
+	rootCmd.AddCommand(selfUpdateCmd)
+}
+
+func runSelfUpdate(cmd *cobra.Command, args []string) error {
+	ctx, cancel := context.WithTimeout(cmd.Context(), 5*time.Minute)
+	defer cancel()
+	client := &http.Client{}
+
+	release, err := update.Latest(ctx, client)
+	if err != nil {
+		return fmt.Errorf("failed to fetch latest release: %w", err)
+	}
+
+	// --check only reports, so --force has nothing to force
+	newer := update.IsNewer(Version, release.TagName)
+	if !newer && (updateCheckOnlyFlag || !updateForceFlag) {
+		fmt.Printf("✓ ebook-renamer %s is up to date (latest release: %s)\n", Version, release.TagName)
+		return nil
+	}
+	if updateCheckOnlyFlag {
+		fmt.Printf("New version available: %s (current: %s)\n", release.TagName, Version)
+		return nil
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to locate executable: %w", err)
+	}
+	if exe, err = filepath.EvalSymlinks(exe); err != nil {
+		return fmt.Errorf("failed to locate executable: %w", err)
+	}
+
+	fmt.Printf("Downloading %s...\n", release.TagName)
+	if err := update.Apply(ctx, client, release, exe); err != nil {
+		return fmt.Errorf("update failed: %w", err)
+	}
+	log.Printf("Updated %s from %s to %s", exe, Version, release.TagName)
+	fmt.Printf("✓ Updated to %s\n", release.TagName)
+	return nil
+}
+
+// startVersionCheck checks for a newer release in the background.
+// The channel receives the newer tag, or is closed without a value if there is none.
+func startVersionCheck(disabled bool) <-chan string {
+	notice := make(chan string, 1)
+	if disabled || os.Getenv(update.DisableEnv) != "" || Version == "dev" {
+		close(notice)
+		return notice
+	}
+
+	go func() {
+		defer close(notice)
+		cachePath, err := update.DefaultCachePath()
+		if err != nil {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		latest, err := update.Check(ctx, &http.Client{}, cachePath, Version, time.Now())
+		if err != nil {
+			log.Printf("Version check failed: %v", err)
+			return
+		}
+		if latest != "" {
+			notice <- latest
+		}
+	}()
+	return notice
+}
+
+// printVersionNotice prints a one-line notice if the version check found a newer release.
+// It never holds up the run: a check still in flight is simply reported next time from the cache.
+func printVersionNotice(notice <-chan string, toStderr bool) {
+	select {
+	case latest, ok := <-notice:
+		if !ok {
+			return
+		}
+		out := os.Stdout
+		if toStderr {
+			// Keep stdout valid JSON
+			out = os.Stderr
+		}
+		fmt.Fprintf(out, "\nℹ️  ebook-renamer %s is available (current: %s), run: ebook-renamer self-update\n", latest, Version)
+	case <-time.After(versionNoticeWait):
+	}
+}
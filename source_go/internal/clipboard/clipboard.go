@@ -0,0 +1,71 @@
+package clipboard
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"unicode/utf16"
+)
+
+// ErrUnavailable is returned when no clipboard tool is installed
+var ErrUnavailable = errors.New("no clipboard tool found (install wl-clipboard, xclip or xsel)")
+
+// Write copies text to the system clipboard using the platform's clipboard tool
+func Write(text string) error {
+	for _, args := range commands(runtime.GOOS, os.Getenv) {
+		path, err := exec.LookPath(args[0])
+		if err != nil {
+			continue
+		}
+		cmd := exec.Command(path, args[1:]...)
+		cmd.Stdin = bytes.NewReader(encode(args[0], text))
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("%s failed: %w: %s", args[0], err, strings.TrimSpace(string(out)))
+		}
+		return nil
+	}
+	return ErrUnavailable
+}
+
+// commands lists the clipboard tools to try, in order of preference
+func commands(goos string, getenv func(string) string) [][]string {
+	switch goos {
+	case "darwin":
+		return [][]string{{"pbcopy"}}
+	case "windows":
+		return [][]string{{"clip.exe"}}
+	}
+
+	var cmds [][]string
+	if getenv("WAYLAND_DISPLAY") != "" {
+		cmds = append(cmds, []string{"wl-copy"})
+	}
+	if getenv("DISPLAY") != "" {
+		cmds = append(cmds, []string{"xclip", "-selection", "clipboard"}, []string{"xsel", "--clipboard", "--input"})
+	}
+	// WSL can reach the Windows clipboard without a display server
+	if getenv("WSL_DISTRO_NAME") != "" {
+		cmds = append(cmds, []string{"clip.exe"})
+	}
+	return cmds
+}
+
+// encode prepares text for a clipboard tool's stdin. clip.exe reads the console code page
+// unless the input starts with a byte order mark, so it gets UTF-16LE; the others take UTF-8.
+func encode(tool, text string) []byte {
+	if tool != "clip.exe" {
+		return []byte(text)
+	}
+	units := utf16.Encode([]rune(text))
+	out := make([]byte, 2, 2+2*len(units))
+	out[0], out[1] = 0xFF, 0xFE
+	for _, u := range units {
+		out = binary.LittleEndian.AppendUint16(out, u)
+	}
+	return out
+}
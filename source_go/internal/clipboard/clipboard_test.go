@@ -0,0 +1,33 @@
+package clipboard
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func env(vars map[string]string) func(string) string {
+	return func(key string) string { return vars[key] }
+}
+
+func TestCommands(t *testing.T) {
+	assert.Equal(t, [][]string{{"pbcopy"}}, commands("darwin", env(nil)))
+	assert.Equal(t, [][]string{{"clip.exe"}}, commands("windows", env(nil)))
+
+	assert.Equal(t, [][]string{
+		{"wl-copy"},
+		{"xclip", "-selection", "clipboard"},
+		{"xsel", "--clipboard", "--input"},
+	}, commands("linux", env(map[string]string{"WAYLAND_DISPLAY": "wayland-0", "DISPLAY": ":0"})))
+
+	assert.Equal(t, [][]string{{"clip.exe"}}, commands("linux", env(map[string]string{"WSL_DISTRO_NAME": "Ubuntu"})))
+	// Headless: nothing to copy to
+	assert.Empty(t, commands("linux", env(nil)))
+}
+
+func TestEncode(t *testing.T) {
+	assert.Equal(t, []byte("整理 3 本"), encode("pbcopy", "整理 3 本"))
+
+	// Byte order mark, then UTF-16LE; the emoji needs a surrogate pair
+	assert.Equal(t, []byte{0xFF, 0xFE, 0x74, 0x65, 0x33, 0x00, 0x3D, 0xD8, 0xDA, 0xDC}, encode("clip.exe", "整3📚"))
+}
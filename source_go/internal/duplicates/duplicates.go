@@ -4,6 +4,7 @@ import (
 	"crypto/md5"
 	"fmt"
 	"io"
+	"math"
 	"os"
 	"path/filepath"
 	"strings"
@@ -107,6 +108,69 @@ func DetectDuplicates(files []*types.FileInfo, skipHash bool) ([][]string, []*ty
 	return duplicateGroups, cleanFiles, nil
 }
 
+// GroupConfidence scores how certain a duplicate group is from its evidence. group[0] is the kept file.
+// Identical content is near certain, with closer names adding a little more. A shared normalized name
+// alone is weaker: it counts for more when the copies agree on size and their original names are close.
+func GroupConfidence(group []*types.FileInfo, skipHash bool) float64 {
+	if len(group) < 2 {
+		return 0
+	}
+	kept := group[0]
+
+	similarity := 1.0
+	sameSize, nearSize := true, true
+	for _, dup := range group[1:] {
+		similarity = math.Min(similarity, nameSimilarity(kept.OriginalName, dup.OriginalName))
+		if dup.Size != kept.Size {
+			sameSize = false
+			larger, smaller := float64(max(dup.Size, kept.Size)), float64(min(dup.Size, kept.Size))
+			if smaller == 0 || larger/smaller > 1.01 {
+				nearSize = false
+			}
+		}
+	}
+
+	if !skipHash {
+		return 0.9 + 0.1*similarity
+	}
+	score := 0.3
+	if sameSize {
+		score = 0.6
+	} else if nearSize {
+		score = 0.45
+	}
+	return score + 0.3*similarity
+}
+
+// nameSimilarity compares two filenames, ignoring case and extension, from 0 (unrelated) to 1 (equal)
+func nameSimilarity(a, b string) float64 {
+	ra := []rune(strings.ToLower(strings.TrimSuffix(a, filepath.Ext(a))))
+	rb := []rune(strings.ToLower(strings.TrimSuffix(b, filepath.Ext(b))))
+	longest := max(len(ra), len(rb))
+	if longest == 0 {
+		return 1
+	}
+
+	// Levenshtein distance, two rows
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return 1 - float64(prev[len(rb)])/float64(longest)
+}
+
 // selectFileToKeep selects the file to keep based on priority: normalized > shortest path > newest
 func selectFileToKeep(files []*types.FileInfo) *types.FileInfo {
 	// Priority 1: Already normalized files (have new_name set)
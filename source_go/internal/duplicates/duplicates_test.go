@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/ebook-renamer/go/internal/normalizer"
 	"github.com/ebook-renamer/go/internal/types"
 	"github.com/stretchr/testify/assert"
 )
@@ -127,3 +128,155 @@ func TestComputeMD5(t *testing.T) {
 	// "test content" md5 -> 9473fdd0d880a43c21b7778d34872157
 	assert.Equal(t, "9473fdd0d880a43c21b7778d34872157", hash)
 }
+
+func sourcesOf(metadata ...types.ParsedMetadata) []mergeSource {
+	sources := make([]mergeSource, len(metadata))
+	for i, m := range metadata {
+		sources[i] = mergeSource{metadata: m}
+	}
+	return sources
+}
+
+func TestMergeMetadata(t *testing.T) {
+	author := "John Smith"
+	year := uint16(2020)
+
+	sources := []types.ParsedMetadata{
+		{Title: "raw scan"},
+		{Authors: &author, Title: "Real Title", Year: &year},
+	}
+	assert.Equal(t, "John Smith - Real Title (2020).pdf", normalizer.GenerateFilename(mergeMetadata(sourcesOf(sources...)).metadata, ".pdf"))
+
+	// Fields are never mixed across sources, however many copies agree on a title
+	sources = append(sources, types.ParsedMetadata{Title: "raw scan"})
+	assert.Equal(t, "John Smith - Real Title (2020).pdf", normalizer.GenerateFilename(mergeMetadata(sourcesOf(sources...)).metadata, ".pdf"))
+
+	// Among equally rich sources, agreement wins
+	other := "Jane Doe"
+	sources = []types.ParsedMetadata{
+		{Authors: &other, Title: "Wrong Title", Year: &year},
+		{Authors: &author, Title: "Real Title", Year: &year},
+		{Authors: &author, Title: "Real Title", Year: &year},
+	}
+	assert.Equal(t, "John Smith - Real Title (2020).pdf", normalizer.GenerateFilename(mergeMetadata(sourcesOf(sources...)).metadata, ".pdf"))
+}
+
+func TestMergeGroupMetadata(t *testing.T) {
+	tmpDir := t.TempDir()
+	rawName := "scan_0001.pdf"
+	goodName := "John Smith - Real Title (2020).pdf"
+	rawNew := "scan 0001.pdf"
+
+	kept := &types.FileInfo{
+		OriginalPath: filepath.Join(tmpDir, rawName),
+		OriginalName: rawName,
+		Extension:    ".pdf",
+		NewName:      &rawNew,
+		NewPath:      filepath.Join(tmpDir, rawNew),
+		Reasons:      []types.ReasonCode{types.ReasonNoiseStripped},
+	}
+	dup := &types.FileInfo{
+		OriginalPath: filepath.Join(tmpDir, "copies", goodName),
+		OriginalName: goodName,
+		Extension:    ".pdf",
+		Confidence:   0.9,
+		Reasons:      []types.ReasonCode{types.ReasonAlreadyNormalized},
+	}
+
+	groups := [][]string{{kept.OriginalPath, dup.OriginalPath}}
+	groups, clean, merged := MergeGroupMetadata(groups, []*types.FileInfo{kept, dup}, []*types.FileInfo{kept}, false)
+
+	assert.Equal(t, 1, merged)
+	assert.Equal(t, kept.OriginalPath, groups[0][0])
+	assert.Equal(t, goodName, *clean[0].NewName)
+	assert.Equal(t, filepath.Join(tmpDir, goodName), clean[0].NewPath)
+	assert.Equal(t, 0.9, clean[0].Confidence)
+	// The kept file's own cleanup codes describe a rename that no longer happens
+	assert.Equal(t, []types.ReasonCode{types.ReasonMetadataMerged}, clean[0].Reasons)
+}
+
+func TestMergeGroupMetadataKeepsCopyWithMergedName(t *testing.T) {
+	tmpDir := t.TempDir()
+	rawName := "scan_0001.pdf"
+	goodName := "John Smith - Real Title (2020).pdf"
+
+	kept := &types.FileInfo{OriginalPath: filepath.Join(tmpDir, rawName), OriginalName: rawName, Extension: ".pdf"}
+	dup := &types.FileInfo{OriginalPath: filepath.Join(tmpDir, goodName), OriginalName: goodName, Extension: ".pdf",
+		Reasons: []types.ReasonCode{types.ReasonAlreadyNormalized}}
+
+	groups := [][]string{{kept.OriginalPath, dup.OriginalPath}}
+	groups, clean, merged := MergeGroupMetadata(groups, []*types.FileInfo{kept, dup}, []*types.FileInfo{kept}, false)
+
+	// Renaming the kept file would overwrite the copy that gets deleted, so they swap roles
+	assert.Equal(t, 1, merged)
+	assert.Equal(t, []string{dup.OriginalPath, kept.OriginalPath}, groups[0])
+	assert.Equal(t, dup, clean[0])
+	assert.Equal(t, dup.OriginalPath, clean[0].NewPath)
+	assert.Equal(t, []types.ReasonCode{types.ReasonAlreadyNormalized, types.ReasonMetadataMerged}, clean[0].Reasons)
+}
+
+func TestMergeGroupMetadataCountsEmbeddedOncePerContent(t *testing.T) {
+	tmpDir := t.TempDir()
+	content := []byte("%PDF-1.4\n1 0 obj\n<< /Title (Wrong Title) /Author (Jane Doe) >>\nendobj\ntrailer\n<< /Info 1 0 R >>\n")
+
+	var files []*types.FileInfo
+	var group []string
+	for _, rel := range []string{"scan.pdf", "a/John Smith - Real Title.pdf", "b/John Smith - Real Title.pdf"} {
+		path := filepath.Join(tmpDir, rel)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.NoError(t, os.WriteFile(path, content, 0644))
+		files = append(files, &types.FileInfo{OriginalPath: path, OriginalName: filepath.Base(path), Extension: ".pdf"})
+		group = append(group, path)
+	}
+
+	// Three identical copies add one embedded source, so the two agreeing filenames win
+	_, clean, merged := MergeGroupMetadata([][]string{group}, files, files[:1], true)
+	assert.Equal(t, 1, merged)
+	assert.Equal(t, "John Smith - Real Title.pdf", *clean[0].NewName)
+}
+
+func TestMergeGroupMetadataUsesWinningSourceConfidence(t *testing.T) {
+	tmpDir := t.TempDir()
+	cleanName := "Real Title.pdf"
+	content := []byte("%PDF-1.4\n1 0 obj\n<< /Title (Real Title) /Author (John Smith) >>\nendobj\ntrailer\n<< /Info 1 0 R >>\n")
+
+	// The kept copy's name is already normalized, but the richer name comes from embedded metadata
+	kept := &types.FileInfo{OriginalPath: filepath.Join(tmpDir, cleanName), OriginalName: cleanName, Extension: ".pdf", Confidence: 1,
+		Reasons: []types.ReasonCode{types.ReasonAlreadyNormalized}}
+	dup := &types.FileInfo{OriginalPath: filepath.Join(tmpDir, "copies", "scan.pdf"), OriginalName: "scan.pdf", Extension: ".pdf", Confidence: 0.2}
+	for _, file := range []*types.FileInfo{kept, dup} {
+		assert.NoError(t, os.MkdirAll(filepath.Dir(file.OriginalPath), 0755))
+		assert.NoError(t, os.WriteFile(file.OriginalPath, content, 0644))
+	}
+
+	groups := [][]string{{kept.OriginalPath, dup.OriginalPath}}
+	_, clean, merged := MergeGroupMetadata(groups, []*types.FileInfo{kept, dup}, []*types.FileInfo{kept}, true)
+	assert.Equal(t, 1, merged)
+	assert.Equal(t, "John Smith - Real Title.pdf", *clean[0].NewName)
+	assert.Equal(t, embeddedConfidence, clean[0].Confidence)
+	assert.Equal(t, []types.ReasonCode{types.ReasonMetadataMerged}, clean[0].Reasons)
+}
+
+func TestGroupConfidence(t *testing.T) {
+	file := func(name string, size uint64) *types.FileInfo {
+		return &types.FileInfo{OriginalName: name, Size: size}
+	}
+
+	// Identical content is always trusted, a little less when the names differ
+	assert.Equal(t, 1.0, GroupConfidence([]*types.FileInfo{file("Book.pdf", 100), file("book.pdf", 100)}, false))
+	hashed := GroupConfidence([]*types.FileInfo{file("Book.pdf", 100), file("scan_0001.pdf", 100)}, false)
+	assert.True(t, hashed >= 0.9 && hashed < 1)
+
+	// Name matches depend on size agreement and how close the original names are
+	sameSize := GroupConfidence([]*types.FileInfo{file("Real Title.pdf", 100), file("Real Title (1).pdf", 100)}, true)
+	nearSize := GroupConfidence([]*types.FileInfo{file("Real Title.pdf", 1000), file("Real Title (1).pdf", 1005)}, true)
+	otherSize := GroupConfidence([]*types.FileInfo{file("Real Title.pdf", 100), file("Real Title (1).pdf", 300)}, true)
+	assert.Greater(t, sameSize, nearSize)
+	assert.Greater(t, nearSize, otherSize)
+	assert.Less(t, sameSize, 1.0)
+
+	farNames := GroupConfidence([]*types.FileInfo{file("Real Title.pdf", 100), file("[libgen] REAL_TITLE v2 scan.pdf", 100)}, true)
+	assert.Greater(t, sameSize, farNames)
+
+	assert.Equal(t, 0.0, GroupConfidence([]*types.FileInfo{file("Book.pdf", 100)}, true))
+}
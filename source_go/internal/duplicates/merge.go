@@ -0,0 +1,198 @@
+package duplicates
+
+import (
+	"log"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/ebook-renamer/go/internal/metadata"
+	"github.com/ebook-renamer/go/internal/normalizer"
+	"github.com/ebook-renamer/go/internal/types"
+)
+
+// Confidence of a name taken from embedded metadata. Producers often leave stale or wrong values
+// there, so such names land in the review tier rather than being applied automatically.
+const embeddedConfidence = 0.6
+
+// Characters that cannot appear in a filename component
+var unsafeFilenameChars = strings.NewReplacer("/", " ", "\\", " ", "\x00", "", ":", " -")
+
+// MergeGroupMetadata gives the kept file of each duplicate group the richest name found across the group.
+// Every member contributes the metadata parsed from its filename and, when readEmbedded is set, the
+// metadata embedded in the file (once per distinct content). Title, author and year are all taken from
+// one source: the most complete one, with agreement between sources breaking ties. The kept file takes
+// that source's confidence: the member's own score for a filename, embeddedConfidence for embedded
+// metadata. Returns the (possibly reordered) groups, the updated clean files and the number of groups
+// whose kept file got a better name.
+func MergeGroupMetadata(groups [][]string, files []*types.FileInfo, cleanFiles []*types.FileInfo, readEmbedded bool) ([][]string, []*types.FileInfo, int) {
+	byPath := make(map[string]*types.FileInfo, len(files))
+	for _, file := range files {
+		byPath[file.OriginalPath] = file
+	}
+
+	merged := 0
+	for gi, group := range groups {
+		if len(group) < 2 {
+			continue
+		}
+		kept := byPath[group[0]]
+		if kept == nil {
+			continue
+		}
+
+		var members []*types.FileInfo
+		var sources []mergeSource
+		seenContent := make(map[string]bool)
+		for _, path := range group {
+			member := byPath[path]
+			if member == nil {
+				continue
+			}
+			members = append(members, member)
+
+			if parsed, err := normalizer.ParseFilename(member.OriginalName, member.Extension); err == nil {
+				sources = append(sources, mergeSource{metadata: parsed, confidence: member.Confidence, reasons: member.Reasons})
+			}
+			if readEmbedded {
+				// Identical copies carry identical metadata, which must not outvote their filenames
+				if hash, err := computeMD5(member.OriginalPath); err == nil {
+					if seenContent[hash] {
+						continue
+					}
+					seenContent[hash] = true
+				}
+				embedded, err := metadata.Extract(member.OriginalPath, member.Extension)
+				if err != nil {
+					log.Printf("Failed to read embedded metadata: %s: %v", member.OriginalPath, err)
+				} else if embedded != nil {
+					sources = append(sources, mergeSource{metadata: sanitizeMetadata(*embedded), confidence: embeddedConfidence})
+				}
+			}
+		}
+
+		best := mergeMetadata(sources)
+		if best.metadata.Title == "" {
+			continue
+		}
+
+		newName := normalizer.GenerateFilename(best.metadata, kept.Extension)
+		currentName := kept.OriginalName
+		if kept.NewName != nil {
+			currentName = *kept.NewName
+		}
+		if newName == currentName {
+			continue
+		}
+
+		// If another copy already carries the merged name, keep that copy instead of renaming over it
+		target := filepath.Join(filepath.Dir(kept.OriginalPath), newName)
+		keeper := kept
+		for _, member := range members[1:] {
+			if member.OriginalPath == target {
+				keeper = member
+				break
+			}
+		}
+
+		if keeper != kept {
+			reordered := []string{keeper.OriginalPath}
+			for _, path := range group {
+				if path != keeper.OriginalPath {
+					reordered = append(reordered, path)
+				}
+			}
+			groups[gi] = reordered
+			for i, file := range cleanFiles {
+				if file == kept {
+					cleanFiles[i] = keeper
+				}
+			}
+		}
+
+		keeper.NewName = &newName
+		keeper.NewPath = target
+		// The name is only as trustworthy as the source it came from
+		keeper.Confidence = best.confidence
+		// The keeper's own normalization no longer applies; the name comes from the winning source
+		keeper.Reasons = nil
+		for _, reason := range best.reasons {
+			if reason != types.ReasonAlreadyNormalized || keeper.OriginalName == newName {
+				keeper.Reasons = append(keeper.Reasons, reason)
+			}
+		}
+		keeper.Reasons = append(keeper.Reasons, types.ReasonMetadataMerged)
+		merged++
+		log.Printf("Merged duplicate metadata: %s -> %s", keeper.OriginalName, newName)
+	}
+
+	return groups, cleanFiles, merged
+}
+
+// mergeSource is one candidate name for a duplicate group and how far it can be trusted
+type mergeSource struct {
+	metadata   types.ParsedMetadata
+	confidence float64
+	reasons    []types.ReasonCode // how a filename source was cleaned up; none for embedded metadata
+}
+
+// mergeMetadata picks the single best source so the merged name is one a copy actually carried.
+// The richest source wins; among equally rich sources the one most others agree with wins, then the earliest.
+func mergeMetadata(sources []mergeSource) mergeSource {
+	counts := make(map[string]int)
+	for _, source := range sources {
+		if source.metadata.Title != "" {
+			counts[metadataKey(source.metadata)]++
+		}
+	}
+
+	var best mergeSource
+	found := false
+	for _, source := range sources {
+		m := source.metadata
+		if m.Title == "" {
+			continue
+		}
+		if !found || richness(m) > richness(best.metadata) ||
+			(richness(m) == richness(best.metadata) && counts[metadataKey(m)] > counts[metadataKey(best.metadata)]) {
+			best = source
+			found = true
+		}
+	}
+	return best
+}
+
+// metadataKey identifies sources that agree on every field
+func metadataKey(m types.ParsedMetadata) string {
+	key := m.Title + "\x00"
+	if m.Authors != nil {
+		key += *m.Authors
+	}
+	key += "\x00"
+	if m.Year != nil {
+		key += strconv.Itoa(int(*m.Year))
+	}
+	return key
+}
+
+// richness scores how complete a metadata source is
+func richness(m types.ParsedMetadata) int {
+	score := 0
+	if m.Authors != nil && *m.Authors != "" {
+		score += 2
+	}
+	if m.Year != nil {
+		score++
+	}
+	return score
+}
+
+// sanitizeMetadata makes embedded metadata safe to use in a filename
+func sanitizeMetadata(m types.ParsedMetadata) types.ParsedMetadata {
+	m.Title = strings.Join(strings.Fields(unsafeFilenameChars.Replace(m.Title)), " ")
+	if m.Authors != nil {
+		authors := strings.Join(strings.Fields(unsafeFilenameChars.Replace(*m.Authors)), " ")
+		m.Authors = &authors
+	}
+	return m
+}
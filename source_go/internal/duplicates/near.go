@@ -0,0 +1,342 @@
+package duplicates
+
+import (
+	"bytes"
+	"compress/zlib"
+	"crypto/md5"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ebook-renamer/go/internal/types"
+)
+
+const (
+	// MaxExtraPages is the largest page count difference still treated as a near-duplicate
+	MaxExtraPages = 2
+	// Files with fewer pages in common are never near-duplicates
+	minCommonPages = 3
+	// Page hashes shared by more files than this (blank pages, stock covers) don't suggest a match
+	maxHashFanout = 50
+	// Larger files are skipped rather than read into memory
+	maxNearDuplicateSize = 512 << 20
+)
+
+var (
+	pdfObjRegex      = regexp.MustCompile(`(\d+)\s+\d+\s+obj\b`)
+	pdfPageRegex     = regexp.MustCompile(`/Type\s*/Page\b`)
+	pdfContentsRegex = regexp.MustCompile(`/Contents\s*(\[[^\]]*\]|\d+\s+\d+\s+R)`)
+	pdfRefRegex      = regexp.MustCompile(`(\d+)\s+\d+\s+R`)
+	pdfRootRegex     = regexp.MustCompile(`/Root\s+(\d+)\s+\d+\s+R`)
+	pdfPagesRegex    = regexp.MustCompile(`/Pages\s+(\d+)\s+\d+\s+R`)
+	pdfKidsRegex     = regexp.MustCompile(`/Kids\s*\[([^\]]*)\]`)
+	pdfLengthRegex   = regexp.MustCompile(`/Length\s+(\d+)(\s+\d+\s+R)?`)
+	pdfFlateRegex    = regexp.MustCompile(`/FlateDecode\b`)
+)
+
+// DetectNearDuplicates finds PDFs that share all pages except for a few extra ones,
+// such as a watermark or cover page added by some sources. Pages are compared by the
+// hash of their decoded content streams and images. The copy with fewer pages is kept.
+func DetectNearDuplicates(files []*types.FileInfo) []types.NearDuplicate {
+	var candidates []*types.FileInfo
+	var pages [][]string
+	for _, file := range files {
+		if strings.ToLower(file.Extension) != ".pdf" || file.IsFailedDownload || file.IsTooSmall || file.Size > maxNearDuplicateSize {
+			continue
+		}
+		hashes, err := pdfPageHashes(file.OriginalPath)
+		if err != nil || len(hashes) < minCommonPages {
+			// Compressed object streams hide page objects; such files are simply not compared
+			continue
+		}
+		candidates = append(candidates, file)
+		pages = append(pages, hashes)
+	}
+
+	// Only files sharing enough page hashes are compared in full
+	byHash := make(map[string][]int)
+	for i, hashes := range pages {
+		seen := make(map[string]bool)
+		for _, h := range hashes {
+			if !seen[h] {
+				seen[h] = true
+				byHash[h] = append(byHash[h], i)
+			}
+		}
+	}
+	shared := make(map[[2]int]int)
+	for _, idxs := range byHash {
+		if len(idxs) > maxHashFanout {
+			continue
+		}
+		for a := 0; a < len(idxs); a++ {
+			for b := a + 1; b < len(idxs); b++ {
+				shared[[2]int{idxs[a], idxs[b]}]++
+			}
+		}
+	}
+
+	type match struct {
+		keep, dup int
+		extra     int
+	}
+	var matches []match
+	for pair, count := range shared {
+		if count < minCommonPages {
+			continue
+		}
+		keep, dup := pair[0], pair[1]
+		if len(pages[keep]) > len(pages[dup]) {
+			keep, dup = dup, keep
+		}
+		// Only added pages qualify: every page of the shorter file must appear, in order, in the longer one.
+		// Files with the same pages are left to exact duplicate detection.
+		extra := len(pages[dup]) - len(pages[keep])
+		if extra == 0 || extra > MaxExtraPages || pageDistance(pages[keep], pages[dup]) != extra {
+			continue
+		}
+		matches = append(matches, match{keep, dup, extra})
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].extra != matches[j].extra {
+			return matches[i].extra < matches[j].extra
+		}
+		return candidates[matches[i].keep].OriginalPath < candidates[matches[j].keep].OriginalPath
+	})
+
+	// Each file is reported as the extra copy at most once
+	var result []types.NearDuplicate
+	reported := make(map[int]bool)
+	for _, m := range matches {
+		keep, dup := m.keep, m.dup
+		if reported[dup] || reported[keep] {
+			continue
+		}
+		reported[dup] = true
+
+		confidence := 0.85
+		if isContiguous(pages[keep], pages[dup]) {
+			// Extra pages only at the start or end: a cover or watermark page
+			confidence = 0.95
+		}
+		result = append(result, types.NearDuplicate{
+			Keep:       candidates[keep].OriginalPath,
+			Duplicate:  candidates[dup].OriginalPath,
+			ExtraPages: m.extra,
+			Confidence: confidence,
+		})
+	}
+
+	log.Printf("Detected %d near-duplicates among %d comparable PDFs", len(result), len(candidates))
+	return result
+}
+
+// pageDistance is the number of pages that must be inserted or removed to turn a into b
+func pageDistance(a, b []string) int {
+	// Longest common subsequence, two rows
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			if a[i-1] == b[j-1] {
+				cur[j] = prev[j-1] + 1
+			} else {
+				cur[j] = max(prev[j], cur[j-1])
+			}
+		}
+		prev, cur = cur, prev
+	}
+	return len(a) + len(b) - 2*prev[len(b)]
+}
+
+// isContiguous reports whether the pages of short appear as one run inside long
+func isContiguous(short, long []string) bool {
+	for start := 0; start+len(short) <= len(long); start++ {
+		match := true
+		for i := range short {
+			if long[start+i] != short[i] {
+				match = false
+				break
+			}
+		}
+		if match {
+			return true
+		}
+	}
+	return false
+}
+
+type pdfObject struct {
+	dict   []byte
+	stream []byte
+}
+
+// pdfPageHashes returns one hash per page, in page tree order
+func pdfPageHashes(filePath string) ([]string, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	// Later definitions win, as with incremental updates
+	objects := make(map[int]pdfObject)
+	locs := pdfObjRegex.FindAllSubmatchIndex(data, -1)
+	for i, loc := range locs {
+		num, err := strconv.Atoi(string(data[loc[2]:loc[3]]))
+		if err != nil {
+			continue
+		}
+		end := len(data)
+		if i+1 < len(locs) {
+			end = locs[i+1][0]
+		}
+		body := data[loc[1]:end]
+		if idx := bytes.Index(body, []byte("endobj")); idx != -1 {
+			body = body[:idx]
+		}
+		objects[num] = splitStream(body)
+	}
+
+	// The last trailer (or cross-reference stream) names the current catalog
+	roots := pdfRootRegex.FindAllSubmatch(data, -1)
+	if len(roots) == 0 {
+		return nil, fmt.Errorf("no document catalog")
+	}
+	rootNum, _ := strconv.Atoi(string(roots[len(roots)-1][1]))
+	m := pdfPagesRegex.FindSubmatch(objects[rootNum].dict)
+	if m == nil {
+		return nil, fmt.Errorf("no page tree")
+	}
+	treeNum, _ := strconv.Atoi(string(m[1]))
+
+	var hashes []string
+	visited := make(map[int]bool)
+	var walk func(num int, inherited []byte)
+	walk = func(num int, inherited []byte) {
+		node, ok := objects[num]
+		if !ok || visited[num] {
+			return
+		}
+		visited[num] = true
+
+		resources := inherited
+		if r := dictEntry(node.dict, "/Resources", objects); r != nil {
+			resources = r
+		}
+		if pdfPageRegex.Match(node.dict) {
+			hashes = append(hashes, pageHash(node, resources, objects))
+			return
+		}
+		if kids := pdfKidsRegex.FindSubmatch(node.dict); kids != nil {
+			for _, ref := range pdfRefRegex.FindAllSubmatch(kids[1], -1) {
+				kid, _ := strconv.Atoi(string(ref[1]))
+				walk(kid, resources)
+			}
+		}
+	}
+	walk(treeNum, nil)
+	return hashes, nil
+}
+
+// pageHash hashes a page's content streams and the XObjects (scanned images, forms) it can draw,
+// since a scanned page's content stream is often just "/Im0 Do"
+func pageHash(page pdfObject, resources []byte, objects map[int]pdfObject) string {
+	hash := md5.New()
+	if m := pdfContentsRegex.FindSubmatch(page.dict); m != nil {
+		for _, ref := range pdfRefRegex.FindAllSubmatch(m[1], -1) {
+			refNum, _ := strconv.Atoi(string(ref[1]))
+			if content, ok := objects[refNum]; ok {
+				hash.Write(decodeStream(content))
+			}
+		}
+	}
+	if xobjects := dictEntry(resources, "/XObject", objects); xobjects != nil {
+		for _, ref := range pdfRefRegex.FindAllSubmatch(xobjects, -1) {
+			refNum, _ := strconv.Atoi(string(ref[1]))
+			if xobject, ok := objects[refNum]; ok {
+				hash.Write(decodeStream(xobject))
+			}
+		}
+	}
+	return string(hash.Sum(nil))
+}
+
+// dictEntry returns the dictionary stored under key, inline or through an indirect reference
+func dictEntry(dict []byte, key string, objects map[int]pdfObject) []byte {
+	idx := bytes.Index(dict, []byte(key))
+	if idx == -1 {
+		return nil
+	}
+	rest := bytes.TrimLeft(dict[idx+len(key):], " \t\r\n")
+	if bytes.HasPrefix(rest, []byte("<<")) {
+		depth := 0
+		for i := 0; i+1 < len(rest); i++ {
+			switch {
+			case rest[i] == '<' && rest[i+1] == '<':
+				depth++
+				i++
+			case rest[i] == '>' && rest[i+1] == '>':
+				depth--
+				i++
+				if depth == 0 {
+					return rest[:i+1]
+				}
+			}
+		}
+		return rest
+	}
+	if m := pdfRefRegex.FindSubmatchIndex(rest); m != nil && m[0] == 0 {
+		num, _ := strconv.Atoi(string(rest[m[2]:m[3]]))
+		if obj, ok := objects[num]; ok {
+			return obj.dict
+		}
+	}
+	return nil
+}
+
+// splitStream separates an object body into its dictionary and raw stream data
+func splitStream(body []byte) pdfObject {
+	idx := bytes.Index(body, []byte("stream"))
+	if idx == -1 {
+		return pdfObject{dict: body}
+	}
+	obj := pdfObject{dict: body[:idx]}
+
+	data := body[idx+len("stream"):]
+	data = bytes.TrimPrefix(data, []byte("\r"))
+	data = bytes.TrimPrefix(data, []byte("\n"))
+	if m := pdfLengthRegex.FindSubmatch(obj.dict); m != nil && m[2] == nil {
+		if n, err := strconv.Atoi(string(m[1])); err == nil && n <= len(data) {
+			obj.stream = data[:n]
+			return obj
+		}
+	}
+	// Indirect or missing length: the stream runs until endstream
+	if end := bytes.LastIndex(data, []byte("endstream")); end != -1 {
+		data = data[:end]
+	}
+	obj.stream = bytes.TrimRight(data, "\r\n")
+	return obj
+}
+
+// decodeStream inflates Flate streams so re-compressed copies of a page hash the same
+func decodeStream(obj pdfObject) []byte {
+	if !pdfFlateRegex.Match(obj.dict) {
+		return obj.stream
+	}
+	r, err := zlib.NewReader(bytes.NewReader(obj.stream))
+	if err != nil {
+		return obj.stream
+	}
+	defer r.Close()
+	decoded, err := io.ReadAll(r)
+	if err != nil && len(decoded) == 0 {
+		return obj.stream
+	}
+	return decoded
+}
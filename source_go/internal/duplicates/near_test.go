@@ -0,0 +1,180 @@
+package duplicates
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ebook-renamer/go/internal/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// writePDF writes a minimal PDF with one content stream per page
+func writePDF(t *testing.T, dir, name string, pages []string, compress bool) *types.FileInfo {
+	return writePDFPages(t, dir, name, pages, nil, compress)
+}
+
+// writeScannedPDF writes a PDF whose pages all draw one image with the same content stream
+func writeScannedPDF(t *testing.T, dir, name string, images []string) *types.FileInfo {
+	pages := make([]string, len(images))
+	for i := range pages {
+		pages[i] = "q 612 0 0 792 0 0 cm /Im0 Do Q"
+	}
+	return writePDFPages(t, dir, name, pages, images, false)
+}
+
+// writePDFPages writes a catalog, a page tree and three objects per page: the page, its content
+// stream and, when images is set, the image XObject it draws
+func writePDFPages(t *testing.T, dir, name string, pages, images []string, compress bool) *types.FileInfo {
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n")
+	buf.WriteString("1 0 obj\n<< /Type /Catalog /Pages 2 0 R >>\nendobj\n")
+	var kids []string
+	for i := range pages {
+		kids = append(kids, fmt.Sprintf("%d 0 R", 3+3*i))
+	}
+	fmt.Fprintf(&buf, "2 0 obj\n<< /Type /Pages /Kids [%s] /Count %d >>\nendobj\n", strings.Join(kids, " "), len(pages))
+	for i, content := range pages {
+		pageNum, contentNum, imageNum := 3+3*i, 4+3*i, 5+3*i
+		resources := ""
+		if images != nil {
+			resources = fmt.Sprintf(" /Resources << /XObject << /Im0 %d 0 R >> >>", imageNum)
+		}
+		fmt.Fprintf(&buf, "%d 0 obj\n<< /Type /Page /Parent 2 0 R%s /Contents %d 0 R >>\nendobj\n", pageNum, resources, contentNum)
+
+		data, filter := []byte(content), ""
+		if compress {
+			var z bytes.Buffer
+			w := zlib.NewWriter(&z)
+			w.Write(data)
+			w.Close()
+			data, filter = z.Bytes(), " /Filter /FlateDecode"
+		}
+		fmt.Fprintf(&buf, "%d 0 obj\n<< /Length %d%s >>\nstream\n%s\nendstream\nendobj\n", contentNum, len(data), filter, data)
+		if images != nil {
+			fmt.Fprintf(&buf, "%d 0 obj\n<< /Type /XObject /Subtype /Image /Length %d >>\nstream\n%s\nendstream\nendobj\n", imageNum, len(images[i]), images[i])
+		}
+	}
+	buf.WriteString("trailer\n<< /Root 1 0 R >>\n%%EOF\n")
+
+	path := filepath.Join(dir, name)
+	assert.NoError(t, os.WriteFile(path, buf.Bytes(), 0644))
+	return &types.FileInfo{OriginalPath: path, OriginalName: name, Extension: ".pdf", Size: uint64(buf.Len())}
+}
+
+func bookPages(n int) []string {
+	pages := make([]string, n)
+	for i := range pages {
+		pages[i] = fmt.Sprintf("BT /F1 12 Tf (Chapter text on page %d) Tj ET", i+1)
+	}
+	return pages
+}
+
+func TestPageDistance(t *testing.T) {
+	assert.Equal(t, 0, pageDistance([]string{"a", "b", "c"}, []string{"a", "b", "c"}))
+	assert.Equal(t, 1, pageDistance([]string{"a", "b", "c"}, []string{"w", "a", "b", "c"}))
+	assert.Equal(t, 2, pageDistance([]string{"a", "b", "c"}, []string{"w", "a", "b", "c", "w"}))
+	assert.Equal(t, 2, pageDistance([]string{"a", "b", "c"}, []string{"a", "x", "c"}))
+}
+
+func TestDetectNearDuplicatesWatermarkPage(t *testing.T) {
+	dir := t.TempDir()
+	pages := bookPages(6)
+	original := writePDF(t, dir, "original.pdf", pages, false)
+	// Same book re-saved with compression and a watermark page in front
+	watermarked := writePDF(t, dir, "watermarked.pdf", append([]string{"BT (Downloaded from somewhere) Tj ET"}, pages...), true)
+	unrelated := writePDF(t, dir, "unrelated.pdf", []string{"p1", "p2", "p3", "p4"}, false)
+
+	result := DetectNearDuplicates([]*types.FileInfo{watermarked, unrelated, original})
+	assert.Equal(t, []types.NearDuplicate{{
+		Keep:       original.OriginalPath,
+		Duplicate:  watermarked.OriginalPath,
+		ExtraPages: 1,
+		Confidence: 0.95,
+	}}, result)
+}
+
+func TestDetectNearDuplicatesIgnoresDifferentBooks(t *testing.T) {
+	dir := t.TempDir()
+	pages := bookPages(8)
+	first := writePDF(t, dir, "first.pdf", pages[:5], false)
+	// Shares three pages but is five pages longer
+	second := writePDF(t, dir, "second.pdf", append(pages[2:5], bookPages(20)[10:17]...), false)
+	// Too few pages to compare reliably
+	tiny := writePDF(t, dir, "tiny.pdf", pages[:2], false)
+	tinyCopy := writePDF(t, dir, "tiny-copy.pdf", append([]string{"cover"}, pages[:2]...), false)
+
+	assert.Empty(t, DetectNearDuplicates([]*types.FileInfo{first, second, tiny, tinyCopy}))
+}
+
+func TestDetectNearDuplicatesOnlyAddedPages(t *testing.T) {
+	dir := t.TempDir()
+	pages := bookPages(6)
+	original := writePDF(t, dir, "original.pdf", pages, false)
+	// A cover in front and an ad at the back
+	padded := writePDF(t, dir, "padded.pdf", append(append([]string{"cover"}, pages...), "ad"), false)
+
+	result := DetectNearDuplicates([]*types.FileInfo{padded, original})
+	assert.Len(t, result, 1)
+	assert.Equal(t, original.OriginalPath, result[0].Keep)
+	assert.Equal(t, 2, result[0].ExtraPages)
+
+	// A replaced page is a different edition, not an extra page
+	replaced := append([]string(nil), pages...)
+	replaced[3] = "errata"
+	edited := writePDF(t, dir, "edited.pdf", append(replaced, "ad"), false)
+	assert.Empty(t, DetectNearDuplicates([]*types.FileInfo{original, edited}))
+
+	// Same pages, different bytes: not a near-duplicate
+	resaved := writePDF(t, dir, "resaved.pdf", pages, true)
+	assert.Empty(t, DetectNearDuplicates([]*types.FileInfo{original, resaved}))
+}
+
+func TestPDFPageHashesFollowsPageTree(t *testing.T) {
+	dir := t.TempDir()
+	pages := bookPages(4)
+	file := writePDF(t, dir, "book.pdf", pages, false)
+
+	// An incremental update appends a page object but lists it second in the page tree
+	f, err := os.OpenFile(file.OriginalPath, os.O_APPEND|os.O_WRONLY, 0644)
+	assert.NoError(t, err)
+	fmt.Fprintf(f, "20 0 obj\n<< /Type /Page /Parent 2 0 R /Contents 21 0 R >>\nendobj\n")
+	fmt.Fprintf(f, "21 0 obj\n<< /Length 9 >>\nstream\nwatermark\nendstream\nendobj\n")
+	fmt.Fprintf(f, "2 0 obj\n<< /Type /Pages /Kids [3 0 R 20 0 R 6 0 R 9 0 R 12 0 R] /Count 5 >>\nendobj\n")
+	fmt.Fprintf(f, "trailer\n<< /Root 1 0 R /Prev 0 >>\n%%%%EOF\n")
+	assert.NoError(t, f.Close())
+
+	original, err := pdfPageHashes(writePDF(t, dir, "original.pdf", pages, false).OriginalPath)
+	assert.NoError(t, err)
+	updated, err := pdfPageHashes(file.OriginalPath)
+	assert.NoError(t, err)
+	assert.Len(t, updated, 5)
+	assert.Equal(t, original[0], updated[0])
+	assert.Equal(t, original[1:], updated[2:])
+	assert.False(t, isContiguous(original, updated))
+}
+
+func TestDetectNearDuplicatesScannedPages(t *testing.T) {
+	dir := t.TempDir()
+	// Every page has the same content stream; only the images tell them apart
+	scans := []string{"scan-1", "scan-2", "scan-3", "scan-4"}
+	original := writeScannedPDF(t, dir, "original.pdf", scans)
+	watermarked := writeScannedPDF(t, dir, "watermarked.pdf", append([]string{"watermark"}, scans...))
+	other := writeScannedPDF(t, dir, "other.pdf", []string{"other-1", "other-2", "other-3", "other-4", "other-5"})
+
+	hashes, err := pdfPageHashes(original.OriginalPath)
+	assert.NoError(t, err)
+	assert.NotEqual(t, hashes[0], hashes[1])
+
+	result := DetectNearDuplicates([]*types.FileInfo{original, watermarked, other})
+	assert.Equal(t, []types.NearDuplicate{{
+		Keep:       original.OriginalPath,
+		Duplicate:  watermarked.OriginalPath,
+		ExtraPages: 1,
+		Confidence: 0.95,
+	}}, result)
+}
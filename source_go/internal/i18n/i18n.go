@@ -0,0 +1,108 @@
+package i18n
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Lang is a display language for user-facing messages
+type Lang string
+
+const (
+	Chinese Lang = "zh"
+	English Lang = "en"
+
+	// Default is the language messages were always written in
+	Default = Chinese
+)
+
+// MessageID is the stable identifier of a message, independent of its display language
+type MessageID string
+
+const (
+	MsgRedownloadIncomplete MessageID = "redownload_incomplete"
+	MsgRedownloadCorrupted  MessageID = "redownload_corrupted"
+	MsgCheckSmallFile       MessageID = "check_small_file"
+	MsgRedownloadSmallFile  MessageID = "redownload_small_file"
+	MsgCheckReadError       MessageID = "check_read_error"
+	MsgCheckUnknownIssue    MessageID = "check_unknown_issue"
+	MsgReviewRename         MessageID = "review_rename"
+	MsgReviewDuplicates     MessageID = "review_duplicates"
+	MsgReviewNearDuplicate  MessageID = "review_near_duplicate"
+	MsgReviewCleanup        MessageID = "review_cleanup"
+)
+
+// Params are the named values substituted into a message, e.g. {file}
+type Params map[string]string
+
+var catalog = map[Lang]map[MessageID]string{
+	Chinese: {
+		MsgRedownloadIncomplete: "重新下载: {file} (未完成下载)",
+		MsgRedownloadCorrupted:  "重新下载: {file} (PDF文件损坏或格式无效)",
+		MsgCheckSmallFile:       "检查文件: {file} (文件过小 {size} 字节，可能需要重新下载)",
+		MsgRedownloadSmallFile:  "检查并重新下载: {file} (文件过小，仅 {size} 字节)",
+		MsgCheckReadError:       "检查文件权限: {file} (无法读取文件)",
+		MsgCheckUnknownIssue:    "检查文件: {file} (未知问题)",
+		MsgReviewRename:         "审核重命名: {file} -> {new_name} (置信度 {confidence})",
+		MsgReviewDuplicates:     "审核重复文件: 保留 {keep}，删除 {copies} 个副本 (置信度 {confidence})",
+		MsgReviewNearDuplicate:  "审核近似重复: {file} 比 {keep} 多 {extra_pages} 页 (可能是水印或封面页，置信度 {confidence})",
+		MsgReviewCleanup:        "审核删除: {file} (置信度 {confidence})",
+	},
+	English: {
+		MsgRedownloadIncomplete: "Re-download: {file} (incomplete download)",
+		MsgRedownloadCorrupted:  "Re-download: {file} (corrupted or invalid PDF)",
+		MsgCheckSmallFile:       "Check file: {file} (only {size} bytes, may need to be re-downloaded)",
+		MsgRedownloadSmallFile:  "Check and re-download: {file} (too small, only {size} bytes)",
+		MsgCheckReadError:       "Check file permissions: {file} (cannot be read)",
+		MsgCheckUnknownIssue:    "Check file: {file} (unknown issue)",
+		MsgReviewRename:         "Review rename: {file} -> {new_name} (confidence {confidence})",
+		MsgReviewDuplicates:     "Review duplicates: keep {keep}, delete {copies} copies (confidence {confidence})",
+		MsgReviewNearDuplicate:  "Review near-duplicate: {file} has {extra_pages} more pages than {keep} (likely watermark or cover pages, confidence {confidence})",
+		MsgReviewCleanup:        "Review deletion: {file} (confidence {confidence})",
+	},
+}
+
+// Langs returns the supported languages
+func Langs() []Lang {
+	langs := make([]Lang, 0, len(catalog))
+	for lang := range catalog {
+		langs = append(langs, lang)
+	}
+	sort.Slice(langs, func(i, j int) bool { return langs[i] < langs[j] })
+	return langs
+}
+
+// Parse validates a language code such as "en" or "zh-CN"
+func Parse(s string) (Lang, error) {
+	code := strings.ToLower(strings.TrimSpace(s))
+	if base, _, found := strings.Cut(code, "-"); found {
+		code = base
+	}
+	if _, ok := catalog[Lang(code)]; !ok {
+		return "", fmt.Errorf("unsupported language %q (supported: %v)", s, Langs())
+	}
+	return Lang(code), nil
+}
+
+// Format renders a message in the given language, falling back to the default language
+func Format(lang Lang, id MessageID, params Params) string {
+	template, ok := catalog[lang][id]
+	if !ok {
+		template, ok = catalog[Default][id]
+	}
+	if !ok {
+		return string(id)
+	}
+
+	pairs := make([]string, 0, 2*len(params))
+	for k, v := range params {
+		pairs = append(pairs, "{"+k+"}", v)
+	}
+	return strings.NewReplacer(pairs...).Replace(template)
+}
+
+// Confidence formats a confidence score the way messages show it
+func Confidence(c float64) string {
+	return fmt.Sprintf("%.2f", c)
+}
@@ -0,0 +1,45 @@
+package i18n
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormat(t *testing.T) {
+	params := Params{"file": "book.pdf", "size": "512"}
+	assert.Equal(t, "检查并重新下载: book.pdf (文件过小，仅 512 字节)", Format(Chinese, MsgRedownloadSmallFile, params))
+	assert.Equal(t, "Check and re-download: book.pdf (too small, only 512 bytes)", Format(English, MsgRedownloadSmallFile, params))
+	// Unknown languages fall back to the default
+	assert.Equal(t, Format(Default, MsgRedownloadSmallFile, params), Format(Lang("fr"), MsgRedownloadSmallFile, params))
+	assert.Equal(t, "unknown_message", Format(English, MessageID("unknown_message"), nil))
+}
+
+func TestCatalogComplete(t *testing.T) {
+	for _, lang := range Langs() {
+		for id, template := range catalog[Default] {
+			translated, ok := catalog[lang][id]
+			assert.True(t, ok, "%s has no %s message", lang, id)
+			// Every placeholder of the default message must be kept
+			for _, field := range strings.FieldsFunc(template, func(r rune) bool { return r == '{' || r == '}' }) {
+				if strings.Contains(template, "{"+field+"}") {
+					assert.Contains(t, translated, "{"+field+"}", "%s %s", lang, id)
+				}
+			}
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	lang, err := Parse("en")
+	assert.NoError(t, err)
+	assert.Equal(t, English, lang)
+
+	lang, err = Parse("zh-CN")
+	assert.NoError(t, err)
+	assert.Equal(t, Chinese, lang)
+
+	_, err = Parse("fr")
+	assert.Error(t, err)
+}
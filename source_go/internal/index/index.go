@@ -0,0 +1,112 @@
+package index
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/ebook-renamer/go/internal/normalizer"
+	"github.com/ebook-renamer/go/internal/types"
+)
+
+// Version is the current index format version
+const Version = 1
+
+// Record describes one book in the library. Path is relative to the library root.
+type Record struct {
+	Path    string  `json:"path"`
+	Authors *string `json:"authors,omitempty"`
+	Title   string  `json:"title"`
+	Year    *uint16 `json:"year,omitempty"`
+	Size    uint64  `json:"size"`
+
+	Series      string `json:"series,omitempty"`
+	SeriesIndex int    `json:"series_index,omitempty"`
+}
+
+// Index is the persisted catalog of the library as of the last run
+type Index struct {
+	Version   int       `json:"version"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Records   []Record  `json:"records"`
+}
+
+// Build creates an index of the files as they are after the run.
+// A file with NewName set is expected at NewPath, otherwise at OriginalPath; files that no
+// longer exist and failed downloads are left out. rel converts absolute paths to
+// library-relative ones.
+func Build(files []*types.FileInfo, rel func(string) string) *Index {
+	idx := &Index{Version: Version, UpdatedAt: time.Now().UTC(), Records: []Record{}}
+	for _, file := range files {
+		if file.IsFailedDownload {
+			continue
+		}
+
+		path, name := file.OriginalPath, file.OriginalName
+		if file.NewName != nil {
+			path, name = file.NewPath, *file.NewName
+		}
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+
+		record := Record{Path: rel(path), Title: name, Size: file.Size}
+		if meta, err := normalizer.ParseFilename(name, file.Extension); err == nil {
+			record.Authors = meta.Authors
+			record.Title = meta.Title
+			record.Year = meta.Year
+		}
+		if series, n, ok := DetectSeries(record.Title); ok {
+			record.Series, record.SeriesIndex = series, n
+		}
+		idx.Records = append(idx.Records, record)
+	}
+
+	sort.Slice(idx.Records, func(i, j int) bool {
+		return idx.Records[i].Path < idx.Records[j].Path
+	})
+	return idx
+}
+
+// Load reads an index file
+func Load(path string) (*Index, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var idx Index
+	if err := json.Unmarshal(data, &idx); err != nil {
+		return nil, fmt.Errorf("invalid index %s: %w", path, err)
+	}
+	if idx.Version > Version {
+		return nil, fmt.Errorf("index %s has unsupported version %d", path, idx.Version)
+	}
+	return &idx, nil
+}
+
+// Save writes the index atomically
+func (idx *Index) Save(path string) error {
+	data, err := json.MarshalIndent(idx, "", "  ")
+	if err != nil {
+		return fmt.Errorf("index serialization failed: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".index-*.json")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,78 @@
+package index
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ebook-renamer/go/internal/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildAndSave(t *testing.T) {
+	tmpDir := t.TempDir()
+	rel := func(p string) string { return strings.TrimPrefix(p, tmpDir+string(filepath.Separator)) }
+
+	newName := "John Smith - Book (2020).pdf"
+	renamed := &types.FileInfo{
+		OriginalPath: filepath.Join(tmpDir, "raw.pdf"),
+		OriginalName: "raw.pdf",
+		Extension:    ".pdf",
+		Size:         2048,
+		NewName:      &newName,
+		NewPath:      filepath.Join(tmpDir, newName),
+	}
+	gone := &types.FileInfo{
+		OriginalPath: filepath.Join(tmpDir, "deleted.pdf"),
+		OriginalName: "deleted.pdf",
+		Extension:    ".pdf",
+	}
+	assert.NoError(t, os.WriteFile(renamed.NewPath, []byte("%PDF-"), 0644))
+
+	idx := Build([]*types.FileInfo{renamed, gone}, rel)
+	assert.Len(t, idx.Records, 1)
+	record := idx.Records[0]
+	assert.Equal(t, newName, record.Path)
+	assert.Equal(t, "John Smith", *record.Authors)
+	assert.Equal(t, "Book", record.Title)
+	assert.Equal(t, uint16(2020), *record.Year)
+
+	indexPath := filepath.Join(tmpDir, "index.json")
+	assert.NoError(t, idx.Save(indexPath))
+	loaded, err := Load(indexPath)
+	assert.NoError(t, err)
+	assert.Equal(t, idx.Records, loaded.Records)
+}
+
+func TestDetectSeries(t *testing.T) {
+	cases := []struct {
+		title  string
+		series string
+		number int
+	}{
+		{"The Art of Computer Programming, Vol. 3", "The Art of Computer Programming", 3},
+		{"Foundation Book 2", "Foundation", 2},
+		{"Dune #4", "Dune", 4},
+		{"Lectures on Physics Volume 1", "Lectures on Physics", 1},
+		{"三体 第2部", "三体", 2},
+	}
+	for _, c := range cases {
+		series, n, ok := DetectSeries(c.title)
+		assert.True(t, ok, c.title)
+		assert.Equal(t, c.series, series, c.title)
+		assert.Equal(t, c.number, n, c.title)
+	}
+
+	for _, title := range []string{"Python Crash Course", "Vol. 3", "Catch 22"} {
+		_, _, ok := DetectSeries(title)
+		assert.False(t, ok, title)
+	}
+}
+
+func TestSplitAuthors(t *testing.T) {
+	assert.Equal(t, []string{"Knuth, Donald"}, SplitAuthors("Knuth, Donald"))
+	assert.Equal(t, []string{"Ronald Graham", "Donald Knuth"}, SplitAuthors("Ronald Graham, Donald Knuth"))
+	assert.Equal(t, []string{"Knuth, Donald", "Graham, Ronald"}, SplitAuthors("Knuth, Donald; Graham, Ronald"))
+	assert.Equal(t, []string{"Abelson", "Sussman"}, SplitAuthors("Abelson & Sussman"))
+}
@@ -0,0 +1,65 @@
+package index
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Volume markers in titles, e.g. "The Art of Computer Programming, Vol. 3", "Dune #2", "三体 第2部"
+var seriesPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)^(.+?)[\s,:;.\-–]*\b(?:vol\.?|volume|book|part|tome|band|no\.)\s*(\d{1,3})\b`),
+	regexp.MustCompile(`^(.+?)[\s,:;\-–]*#\s*(\d{1,3})\b`),
+	regexp.MustCompile(`^(.+?)\s*第\s*(\d{1,3})\s*[卷册部集]`),
+}
+
+var authorSeparators = regexp.MustCompile(`\s*(?:;|&|\band\b|、)\s*`)
+
+// DetectSeries extracts the series name and volume number from a title
+func DetectSeries(title string) (string, int, bool) {
+	for _, re := range seriesPatterns {
+		m := re.FindStringSubmatch(title)
+		if m == nil {
+			continue
+		}
+		name := strings.TrimRightFunc(m[1], func(r rune) bool {
+			return unicode.IsSpace(r) || unicode.IsPunct(r)
+		})
+		n, err := strconv.Atoi(m[2])
+		if err != nil || n <= 0 || !strings.ContainsFunc(name, unicode.IsLetter) {
+			continue
+		}
+		return name, n, true
+	}
+	return "", 0, false
+}
+
+// SplitAuthors splits an authors field into individual names.
+// Commas only separate authors when every part is a full name, so "Knuth, Donald" stays one author.
+func SplitAuthors(authors string) []string {
+	var parts []string
+	for _, p := range authorSeparators.Split(authors, -1) {
+		if p = strings.TrimSpace(p); p != "" {
+			parts = append(parts, p)
+		}
+	}
+
+	var names []string
+	for _, p := range parts {
+		commaParts := strings.Split(p, ",")
+		fullNames := len(commaParts) > 1
+		for i := range commaParts {
+			commaParts[i] = strings.TrimSpace(commaParts[i])
+			if !strings.Contains(commaParts[i], " ") {
+				fullNames = false
+			}
+		}
+		if fullNames {
+			names = append(names, commaParts...)
+		} else {
+			names = append(names, p)
+		}
+	}
+	return names
+}
@@ -0,0 +1,107 @@
+package journal
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"time"
+)
+
+// Op identifies a journaled operation
+type Op string
+
+const (
+	OpRename Op = "rename"
+	OpDelete Op = "delete"
+)
+
+// Entry is one line of the journal. Paths are relative to the library root
+// so the journal stays valid when the library moves.
+type Entry struct {
+	Time   time.Time `json:"time"`
+	Op     Op        `json:"op"`
+	Path   string    `json:"path"`
+	Target string    `json:"target,omitempty"`
+	Reason string    `json:"reason,omitempty"`
+	User   string    `json:"user,omitempty"`
+	Host   string    `json:"host,omitempty"`
+}
+
+// Journal appends operation entries to a JSON Lines file
+type Journal struct {
+	file *os.File
+	rel  func(string) string
+	user string
+	host string
+}
+
+// Open opens the journal for appending. rel converts absolute paths to library-relative ones;
+// user and host attribute every recorded entry to whoever performed it.
+func Open(path string, rel func(string) string, user, host string) (*Journal, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open journal: %w", err)
+	}
+	return &Journal{file: file, rel: rel, user: user, host: host}, nil
+}
+
+// Record appends one operation to the journal
+func (j *Journal) Record(op Op, path, target, reason string) error {
+	entry := Entry{
+		Time:   time.Now().UTC(),
+		Op:     op,
+		Path:   j.rel(path),
+		Reason: reason,
+		User:   j.user,
+		Host:   j.host,
+	}
+	if target != "" {
+		entry.Target = j.rel(target)
+	}
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	_, err = j.file.Write(append(line, '\n'))
+	return err
+}
+
+// RecordOrLog records an operation that already happened. It can't be undone, so a failed
+// journal write is only logged.
+func (j *Journal) RecordOrLog(op Op, path, target, reason string) {
+	if err := j.Record(op, path, target, reason); err != nil {
+		log.Printf("Failed to write journal entry for %s: %v", path, err)
+	}
+}
+
+// Close closes the journal file
+func (j *Journal) Close() error {
+	return j.file.Close()
+}
+
+// Read loads all entries of a journal file
+func Read(path string) ([]Entry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("invalid journal line %d: %w", lineNo, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
@@ -0,0 +1,38 @@
+package journal
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecordAndRead(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "journal.jsonl")
+	rel := func(p string) string { return strings.TrimPrefix(p, "/lib/") }
+
+	j, err := Open(path, rel, "alice", "nas")
+	assert.NoError(t, err)
+	assert.NoError(t, j.Record(OpRename, "/lib/raw.pdf", "/lib/Author - Title.pdf", "normalized"))
+	assert.NoError(t, j.Record(OpDelete, "/lib/dup.pdf", "", "cleanup"))
+	assert.NoError(t, j.Close())
+
+	// Reopening appends instead of truncating
+	j, err = Open(path, rel, "alice", "nas")
+	assert.NoError(t, err)
+	assert.NoError(t, j.Record(OpDelete, "/lib/other.pdf", "", "cleanup"))
+	assert.NoError(t, j.Close())
+
+	entries, err := Read(path)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 3)
+	assert.Equal(t, OpRename, entries[0].Op)
+	assert.Equal(t, "raw.pdf", entries[0].Path)
+	assert.Equal(t, "Author - Title.pdf", entries[0].Target)
+	assert.Empty(t, entries[1].Target)
+	assert.False(t, entries[0].Time.IsZero())
+	assert.Equal(t, "alice", entries[2].User)
+	assert.Equal(t, "nas", entries[2].Host)
+}
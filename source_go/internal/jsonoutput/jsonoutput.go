@@ -83,6 +83,61 @@ func FromResults(cleanFiles []*types.FileInfo, duplicateGroups [][]string, files
 	return output, nil
 }
 
+// AddNearDuplicates adds near-duplicates to the output with library-relative paths
+func AddNearDuplicates(output *types.OperationsOutput, nearDuplicates []types.NearDuplicate, targetDir string) {
+	for _, nd := range nearDuplicates {
+		nd.Keep = makeRelativePath(nd.Keep, targetDir)
+		nd.Duplicate = makeRelativePath(nd.Duplicate, targetDir)
+		output.NearDuplicates = append(output.NearDuplicates, nd)
+	}
+	sort.Slice(output.NearDuplicates, func(i, j int) bool {
+		return output.NearDuplicates[i].Duplicate < output.NearDuplicates[j].Duplicate
+	})
+}
+
+// AddReasonCodes attaches stable reason codes to every rename, delete and todo item.
+// deleteReasons maps the absolute path of each small or corrupted file to why it is deleted.
+func AddReasonCodes(output *types.OperationsOutput, cleanFiles []*types.FileInfo, deleteReasons map[string]types.ReasonCode, skipHash bool, targetDir string) {
+	renameReasons := make(map[string][]types.ReasonCode)
+	for _, file := range cleanFiles {
+		renameReasons[makeRelativePath(file.OriginalPath, targetDir)] = file.Reasons
+	}
+	for i := range output.Renames {
+		output.Renames[i].ReasonCodes = renameReasons[output.Renames[i].From]
+	}
+
+	dupReason := types.ReasonDupContentHash
+	if skipHash {
+		dupReason = types.ReasonDupSameName
+	}
+	for i := range output.DuplicateDeletes {
+		output.DuplicateDeletes[i].ReasonCodes = []types.ReasonCode{dupReason}
+	}
+
+	relReasons := make(map[string]types.ReasonCode)
+	for path, reason := range deleteReasons {
+		relReasons[makeRelativePath(path, targetDir)] = reason
+	}
+	for i := range output.SmallOrCorruptedDeletes {
+		if reason, ok := relReasons[output.SmallOrCorruptedDeletes[i].Path]; ok {
+			output.SmallOrCorruptedDeletes[i].ReasonCodes = []types.ReasonCode{reason}
+		}
+	}
+
+	for i := range output.TodoItems {
+		output.TodoItems[i].ReasonCodes = todoReasons[output.TodoItems[i].Category]
+	}
+}
+
+// Reason codes of each todo category
+var todoReasons = map[string][]types.ReasonCode{
+	"failed_download": {types.ReasonIncompleteSuffix},
+	"corrupted":       {types.ReasonCorruptHeader},
+	"too_small":       {types.ReasonTooSmall},
+	"needs_review":    {types.ReasonLowConfidence},
+	"near_duplicate":  {types.ReasonNearDupPages},
+}
+
 // ToJSON converts the OperationsOutput to a JSON string
 func ToJSON(output *types.OperationsOutput) (string, error) {
 	jsonBytes, err := json.MarshalIndent(output, "", "  ")
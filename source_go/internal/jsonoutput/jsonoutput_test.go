@@ -0,0 +1,64 @@
+package jsonoutput
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/ebook-renamer/go/internal/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddReasonCodes(t *testing.T) {
+	lib := t.TempDir()
+	path := func(name string) string { return filepath.Join(lib, name) }
+
+	newName := "John Smith - Book.pdf"
+	renamed := &types.FileInfo{
+		OriginalPath: path("john_smith-book [libgen].pdf"),
+		NewName:      &newName,
+		NewPath:      path(newName),
+		Reasons:      []types.ReasonCode{types.ReasonBracketsRemoved, types.ReasonAuthorExtracted},
+	}
+	groups := [][]string{{path(newName), path("copies/book.pdf")}}
+	filesToDelete := []string{path("part.pdf.download"), path("broken.pdf"), path("tiny.pdf"), path("unknown.pdf")}
+	deleteReasons := map[string]types.ReasonCode{
+		path("part.pdf.download"): types.ReasonIncompleteSuffix,
+		path("broken.pdf"):        types.ReasonCorruptHeader,
+		path("tiny.pdf"):          types.ReasonTooSmall,
+	}
+	var todoItems []types.TodoItem
+	for _, category := range []string{"failed_download", "corrupted", "too_small", "needs_review", "near_duplicate"} {
+		todoItems = append(todoItems, types.TodoItem{Category: category, File: category + ".pdf"})
+	}
+
+	output, err := FromResults([]*types.FileInfo{renamed}, groups, filesToDelete, todoItems, lib)
+	assert.NoError(t, err)
+	AddReasonCodes(output, []*types.FileInfo{renamed}, deleteReasons, false, lib)
+
+	assert.Equal(t, []types.ReasonCode{types.ReasonBracketsRemoved, types.ReasonAuthorExtracted}, output.Renames[0].ReasonCodes)
+	assert.Equal(t, []types.ReasonCode{types.ReasonDupContentHash}, output.DuplicateDeletes[0].ReasonCodes)
+
+	deletes := make(map[string][]types.ReasonCode)
+	for _, op := range output.SmallOrCorruptedDeletes {
+		deletes[op.Path] = op.ReasonCodes
+	}
+	assert.Equal(t, map[string][]types.ReasonCode{
+		"broken.pdf":        {types.ReasonCorruptHeader},
+		"part.pdf.download": {types.ReasonIncompleteSuffix},
+		"tiny.pdf":          {types.ReasonTooSmall},
+		// No recorded reason: left without codes rather than guessed
+		"unknown.pdf": nil,
+	}, deletes)
+
+	assert.Equal(t, []types.ReasonCode{types.ReasonIncompleteSuffix}, output.TodoItems[0].ReasonCodes)
+	assert.Equal(t, []types.ReasonCode{types.ReasonCorruptHeader}, output.TodoItems[1].ReasonCodes)
+	assert.Equal(t, []types.ReasonCode{types.ReasonTooSmall}, output.TodoItems[2].ReasonCodes)
+	assert.Equal(t, []types.ReasonCode{types.ReasonLowConfidence}, output.TodoItems[3].ReasonCodes)
+	assert.Equal(t, []types.ReasonCode{types.ReasonNearDupPages}, output.TodoItems[4].ReasonCodes)
+
+	// Without hashing, duplicates only share a name
+	output, err = FromResults(nil, groups, nil, nil, lib)
+	assert.NoError(t, err)
+	AddReasonCodes(output, nil, nil, true, lib)
+	assert.Equal(t, []types.ReasonCode{types.ReasonDupSameName}, output.DuplicateDeletes[0].ReasonCodes)
+}
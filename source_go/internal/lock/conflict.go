@@ -0,0 +1,61 @@
+package lock
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/ebook-renamer/go/internal/types"
+)
+
+// CheckUnchanged verifies that a file still looks the way it did when it was scanned.
+// Another user working on the same library may have renamed, replaced or removed it since.
+func CheckUnchanged(fileInfo *types.FileInfo) error {
+	info, err := os.Stat(fileInfo.OriginalPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("file disappeared since scan")
+		}
+		return err
+	}
+	if uint64(info.Size()) != fileInfo.Size || !info.ModTime().Equal(fileInfo.ModifiedTime) {
+		return fmt.Errorf("file changed since scan")
+	}
+	return nil
+}
+
+// CheckRenameTarget verifies that renaming would not overwrite a different file
+func CheckRenameTarget(fileInfo *types.FileInfo) error {
+	target, err := os.Stat(fileInfo.NewPath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	// Case-only renames on case-insensitive filesystems point at the file itself
+	if source, err := os.Stat(fileInfo.OriginalPath); err == nil && os.SameFile(source, target) {
+		return nil
+	}
+	return fmt.Errorf("target already exists: %s", fileInfo.NewPath)
+}
+
+// CheckRename verifies a planned rename still applies to the file that was scanned
+func CheckRename(fileInfo *types.FileInfo) error {
+	if err := CheckUnchanged(fileInfo); err != nil {
+		return err
+	}
+	return CheckRenameTarget(fileInfo)
+}
+
+// CheckKept verifies the kept file of a duplicate group is still the file that was scanned,
+// so its copies may be deleted
+func CheckKept(kept *types.FileInfo) error {
+	if kept == nil {
+		return nil
+	}
+	if err := CheckUnchanged(kept); err != nil {
+		return fmt.Errorf("kept copy: %w", err)
+	}
+	return nil
+}
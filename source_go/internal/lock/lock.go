@@ -0,0 +1,184 @@
+package lock
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/user"
+	"path/filepath"
+	"time"
+)
+
+// FileName is the advisory lock file created in the library root while operations run.
+// It lives in the library rather than the state directory so that every machine sharing
+// the library sees it, whether or not portable mode is used.
+const FileName = ".ebook-renamer.lock"
+
+// StaleAfter is how old a lock may get before it is considered abandoned
+const StaleAfter = 12 * time.Hour
+
+// Holder describes who holds a lock
+type Holder struct {
+	User       string    `json:"user"`
+	Host       string    `json:"host"`
+	PID        int       `json:"pid"`
+	AcquiredAt time.Time `json:"acquired_at"`
+}
+
+func (h Holder) String() string {
+	return fmt.Sprintf("%s@%s (pid %d) since %s", h.User, h.Host, h.PID, h.AcquiredAt.Local().Format("2006-01-02 15:04:05"))
+}
+
+func (h Holder) same(other Holder) bool {
+	return h.User == other.User && h.Host == other.Host && h.PID == other.PID && h.AcquiredAt.Equal(other.AcquiredAt)
+}
+
+// LockedError is returned when another user or process holds the lock
+type LockedError struct {
+	Holder Holder
+}
+
+func (e *LockedError) Error() string {
+	return fmt.Sprintf("library is locked by %s", e.Holder)
+}
+
+// Lock is an acquired advisory lock on a library
+type Lock struct {
+	path   string
+	holder Holder
+}
+
+// Identity returns the current user and host, used for locks and journal attribution
+func Identity() (string, string) {
+	name := os.Getenv("USER")
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		name = u.Username
+	}
+	if name == "" {
+		name = "unknown"
+	}
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		host = "unknown"
+	}
+	return name, host
+}
+
+// Acquire takes the advisory lock for the library. A stale lock, or any lock when force is
+// set, is broken and taken over; otherwise a *LockedError describes the current holder.
+func Acquire(libraryRoot string, force bool) (*Lock, error) {
+	path := filepath.Join(libraryRoot, FileName)
+	name, host := Identity()
+	holder := Holder{User: name, Host: host, PID: os.Getpid(), AcquiredAt: time.Now().UTC()}
+
+	data, err := json.Marshal(holder)
+	if err != nil {
+		return nil, err
+	}
+
+	for attempt := 0; attempt < 2; attempt++ {
+		file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			_, werr := file.Write(data)
+			cerr := file.Close()
+			if werr != nil || cerr != nil {
+				os.Remove(path)
+				return nil, fmt.Errorf("failed to write lock file: %w", errors.Join(werr, cerr))
+			}
+			return &Lock{path: path, holder: holder}, nil
+		}
+		if !os.IsExist(err) {
+			return nil, fmt.Errorf("failed to create lock file: %w", err)
+		}
+
+		seen, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		current, err := parseHolder(seen, err)
+		if err == nil && !force && time.Since(current.AcquiredAt) < StaleAfter {
+			return nil, &LockedError{Holder: current}
+		}
+
+		// Stale, unreadable or forcibly broken: move it aside and retry once
+		if err := breakLock(path, seen); err != nil {
+			return nil, err
+		}
+	}
+
+	return nil, fmt.Errorf("failed to acquire lock %s", path)
+}
+
+// Release removes the lock file if it still belongs to this lock
+func (l *Lock) Release() error {
+	current, err := readHolder(l.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	if !current.same(l.holder) {
+		return fmt.Errorf("lock was taken over by %s", current)
+	}
+	return os.Remove(l.path)
+}
+
+// breakLock moves the lock file aside under a unique name, so of several runs breaking the same
+// stale lock only one succeeds. If the file moved is no longer the one that was judged stale,
+// another run took the lock in between and it is put back.
+func breakLock(path string, seen []byte) error {
+	aside := fmt.Sprintf("%s.broken-%d-%d", path, os.Getpid(), time.Now().UnixNano())
+	if err := os.Rename(path, aside); err != nil {
+		if os.IsNotExist(err) {
+			// Someone else broke it first
+			return nil
+		}
+		return fmt.Errorf("failed to break lock: %w", err)
+	}
+	defer os.Remove(aside)
+
+	moved, err := os.ReadFile(aside)
+	if err != nil {
+		return fmt.Errorf("failed to break lock: %w", err)
+	}
+	if !bytes.Equal(moved, seen) {
+		// Linking fails rather than overwrites if yet another run created a lock meanwhile
+		if err := os.Link(aside, path); err != nil && !os.IsExist(err) {
+			return fmt.Errorf("failed to restore lock taken over during break: %w", err)
+		}
+		holder, err := parseHolder(moved, nil)
+		if err != nil {
+			return err
+		}
+		return &LockedError{Holder: holder}
+	}
+	return nil
+}
+
+// ReleaseOrLog releases the lock for use in a defer. A lock left behind blocks the next run
+// until it goes stale, so a failure is logged with the lock file's path.
+func (l *Lock) ReleaseOrLog() {
+	if err := l.Release(); err != nil {
+		log.Printf("Failed to release lock %s: %v", l.path, err)
+	}
+}
+
+func readHolder(path string) (Holder, error) {
+	data, err := os.ReadFile(path)
+	return parseHolder(data, err)
+}
+
+func parseHolder(data []byte, err error) (Holder, error) {
+	var holder Holder
+	if err != nil {
+		return holder, err
+	}
+	if err := json.Unmarshal(data, &holder); err != nil {
+		return holder, fmt.Errorf("invalid lock file: %w", err)
+	}
+	return holder, nil
+}
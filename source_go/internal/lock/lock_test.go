@@ -0,0 +1,109 @@
+package lock
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ebook-renamer/go/internal/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAcquireAndRelease(t *testing.T) {
+	libDir := t.TempDir()
+
+	l, err := Acquire(libDir, false)
+	assert.NoError(t, err)
+	assert.FileExists(t, filepath.Join(libDir, FileName))
+
+	// A second run sees the holder
+	_, err = Acquire(libDir, false)
+	var locked *LockedError
+	assert.True(t, errors.As(err, &locked))
+	assert.Equal(t, os.Getpid(), locked.Holder.PID)
+
+	assert.NoError(t, l.Release())
+	assert.NoFileExists(t, filepath.Join(libDir, FileName))
+}
+
+func TestAcquireBreaksStaleLock(t *testing.T) {
+	libDir := t.TempDir()
+	stale := Holder{User: "alice", Host: "laptop", PID: 1, AcquiredAt: time.Now().Add(-2 * StaleAfter)}
+	data, _ := json.Marshal(stale)
+	assert.NoError(t, os.WriteFile(filepath.Join(libDir, FileName), data, 0644))
+
+	l, err := Acquire(libDir, false)
+	assert.NoError(t, err)
+	assert.NoError(t, l.Release())
+}
+
+func TestBreakLockKeepsLockTakenOverMeanwhile(t *testing.T) {
+	libDir := t.TempDir()
+	path := filepath.Join(libDir, FileName)
+	stale, _ := json.Marshal(Holder{User: "alice", Host: "laptop", PID: 1, AcquiredAt: time.Now().Add(-2 * StaleAfter)})
+
+	// Another run broke the stale lock and took it before we got to it
+	fresh := Holder{User: "bob", Host: "desktop", PID: 2, AcquiredAt: time.Now().UTC()}
+	data, _ := json.Marshal(fresh)
+	assert.NoError(t, os.WriteFile(path, data, 0644))
+
+	err := breakLock(path, stale)
+	var locked *LockedError
+	assert.True(t, errors.As(err, &locked))
+	assert.Equal(t, "bob", locked.Holder.User)
+
+	// The other run's lock is back in place and nothing is left behind
+	current, err := readHolder(path)
+	assert.NoError(t, err)
+	assert.True(t, current.same(fresh))
+	entries, _ := os.ReadDir(libDir)
+	assert.Len(t, entries, 1)
+}
+
+func TestAcquireForce(t *testing.T) {
+	libDir := t.TempDir()
+	other, err := Acquire(libDir, false)
+	assert.NoError(t, err)
+
+	l, err := Acquire(libDir, true)
+	assert.NoError(t, err)
+
+	// The broken lock must not remove the new holder's file
+	assert.Error(t, other.Release())
+	assert.FileExists(t, filepath.Join(libDir, FileName))
+	assert.NoError(t, l.Release())
+}
+
+func TestConflictChecks(t *testing.T) {
+	libDir := t.TempDir()
+	path := filepath.Join(libDir, "book.pdf")
+	assert.NoError(t, os.WriteFile(path, []byte("%PDF-1.4"), 0644))
+	info, _ := os.Stat(path)
+
+	newPath := filepath.Join(libDir, "Author - Book.pdf")
+	newName := "Author - Book.pdf"
+	fileInfo := &types.FileInfo{
+		OriginalPath: path,
+		Size:         uint64(info.Size()),
+		ModifiedTime: info.ModTime(),
+		NewName:      &newName,
+		NewPath:      newPath,
+	}
+
+	assert.NoError(t, CheckUnchanged(fileInfo))
+	assert.NoError(t, CheckRenameTarget(fileInfo))
+
+	// Someone else created the target in the meantime
+	assert.NoError(t, os.WriteFile(newPath, []byte("other"), 0644))
+	assert.Error(t, CheckRenameTarget(fileInfo))
+
+	// Someone else modified the source
+	assert.NoError(t, os.WriteFile(path, []byte("%PDF-1.4 edited"), 0644))
+	assert.Error(t, CheckUnchanged(fileInfo))
+
+	assert.NoError(t, os.Remove(path))
+	assert.Error(t, CheckUnchanged(fileInfo))
+}
@@ -0,0 +1,288 @@
+package metadata
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/ebook-renamer/go/internal/types"
+)
+
+// PDF Info dictionaries usually sit near the start or the end of the file
+const pdfChunkSize = 256 * 1024
+
+var (
+	pdfTitleRegex  = regexp.MustCompile(`/Title\s*(\((?:\\.|[^\\)])*\)|<[0-9A-Fa-f\s]*>)`)
+	pdfAuthorRegex = regexp.MustCompile(`/Author\s*(\((?:\\.|[^\\)])*\)|<[0-9A-Fa-f\s]*>)`)
+	// Found in the classic trailer and in cross-reference stream dictionaries
+	pdfInfoRefRegex = regexp.MustCompile(`/Info\s+(\d+)\s+(\d+)\s+R`)
+	yearRegex       = regexp.MustCompile(`\b(19|20)\d{2}\b`)
+)
+
+// Values producers put into metadata fields that say nothing about the book
+var (
+	junkValues    = []string{"untitled", "unknown", "title", "author", "anonymous"}
+	junkFragments = []string{"microsoft word", "untitled", ".doc", ".tex", ".dvi", ".indd"}
+)
+
+// Extract reads metadata embedded in the file (PDF Info dictionary or EPUB OPF).
+// It returns nil if the file carries no usable metadata.
+func Extract(filePath, extension string) (*types.ParsedMetadata, error) {
+	switch strings.ToLower(extension) {
+	case ".pdf":
+		return extractPDF(filePath)
+	case ".epub":
+		return extractEPUB(filePath)
+	default:
+		return nil, nil
+	}
+}
+
+func extractPDF(filePath string) (*types.ParsedMetadata, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	// Read the head and the tail, or the whole file if it is small
+	var data []byte
+	if info.Size() <= 2*pdfChunkSize {
+		data, err = io.ReadAll(file)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		head := make([]byte, pdfChunkSize)
+		if _, err := io.ReadFull(file, head); err != nil {
+			return nil, err
+		}
+		tail := make([]byte, pdfChunkSize)
+		if _, err := file.ReadAt(tail, info.Size()-pdfChunkSize); err != nil && err != io.EOF {
+			return nil, err
+		}
+		data = append(head, tail...)
+	}
+
+	// Only the Info dictionary counts: outline entries and annotations use /Title too
+	dict := pdfInfoDict(data)
+	if dict == nil {
+		return nil, nil
+	}
+
+	var title, author string
+	if m := pdfTitleRegex.FindSubmatch(dict); m != nil {
+		title = cleanValue(decodePDFString(m[1]))
+	}
+	if m := pdfAuthorRegex.FindSubmatch(dict); m != nil {
+		author = cleanValue(decodePDFString(m[1]))
+	}
+
+	return buildMetadata(title, []string{author}, ""), nil
+}
+
+// pdfInfoDict returns the body of the object the trailer's /Info entry points at, or nil if there is none.
+// The last reference and the last definition win, since incremental updates append newer ones.
+func pdfInfoDict(data []byte) []byte {
+	refs := pdfInfoRefRegex.FindAllSubmatch(data, -1)
+	if len(refs) == 0 {
+		return nil
+	}
+	ref := refs[len(refs)-1]
+
+	objRegex := regexp.MustCompile(fmt.Sprintf(`(?:^|[^0-9])%s\s+%s\s+obj\b`, ref[1], ref[2]))
+	objs := objRegex.FindAllIndex(data, -1)
+	if len(objs) == 0 {
+		return nil
+	}
+	body := data[objs[len(objs)-1][1]:]
+	if end := bytes.Index(body, []byte("endobj")); end >= 0 {
+		body = body[:end]
+	}
+	return body
+}
+
+// decodePDFString decodes a PDF literal "(...)" or hex "<...>" string
+func decodePDFString(raw []byte) string {
+	var b []byte
+	if raw[0] == '<' {
+		digits := strings.Join(strings.Fields(string(raw[1:len(raw)-1])), "")
+		if len(digits)%2 == 1 {
+			digits += "0"
+		}
+		decoded, err := hex.DecodeString(digits)
+		if err != nil {
+			return ""
+		}
+		b = decoded
+	} else {
+		b = unescapePDFLiteral(raw[1 : len(raw)-1])
+	}
+
+	// UTF-16BE with byte order mark
+	if len(b) >= 2 && b[0] == 0xFE && b[1] == 0xFF {
+		units := make([]uint16, 0, (len(b)-2)/2)
+		for i := 2; i+1 < len(b); i += 2 {
+			units = append(units, uint16(b[i])<<8|uint16(b[i+1]))
+		}
+		return string(utf16.Decode(units))
+	}
+
+	// PDFDocEncoding is close enough to Latin-1 for names and titles
+	runes := make([]rune, len(b))
+	for i, c := range b {
+		runes[i] = rune(c)
+	}
+	return string(runes)
+}
+
+func unescapePDFLiteral(s []byte) []byte {
+	var out []byte
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 >= len(s) {
+			out = append(out, s[i])
+			continue
+		}
+		i++
+		switch c := s[i]; c {
+		case 'n':
+			out = append(out, '\n')
+		case 'r':
+			out = append(out, '\r')
+		case 't':
+			out = append(out, '\t')
+		case 'b':
+			out = append(out, '\b')
+		case 'f':
+			out = append(out, '\f')
+		case '\n':
+			// Line continuation
+		default:
+			if c >= '0' && c <= '7' {
+				end := i + 1
+				for end < len(s) && end < i+3 && s[end] >= '0' && s[end] <= '7' {
+					end++
+				}
+				v, _ := strconv.ParseUint(string(s[i:end]), 8, 8)
+				out = append(out, byte(v))
+				i = end - 1
+			} else {
+				out = append(out, c)
+			}
+		}
+	}
+	return out
+}
+
+type epubContainer struct {
+	Rootfiles []struct {
+		FullPath string `xml:"full-path,attr"`
+	} `xml:"rootfiles>rootfile"`
+}
+
+type epubPackage struct {
+	Titles   []string `xml:"metadata>title"`
+	Creators []string `xml:"metadata>creator"`
+	Dates    []string `xml:"metadata>date"`
+}
+
+func extractEPUB(filePath string) (*types.ParsedMetadata, error) {
+	archive, err := zip.OpenReader(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer archive.Close()
+
+	var container epubContainer
+	if err := decodeZipXML(&archive.Reader, "META-INF/container.xml", &container); err != nil {
+		return nil, err
+	}
+	if len(container.Rootfiles) == 0 {
+		return nil, fmt.Errorf("epub has no rootfile")
+	}
+
+	var pkg epubPackage
+	if err := decodeZipXML(&archive.Reader, path.Clean(container.Rootfiles[0].FullPath), &pkg); err != nil {
+		return nil, err
+	}
+
+	var title, date string
+	if len(pkg.Titles) > 0 {
+		title = cleanValue(pkg.Titles[0])
+	}
+	if len(pkg.Dates) > 0 {
+		date = pkg.Dates[0]
+	}
+	return buildMetadata(title, pkg.Creators, date), nil
+}
+
+func decodeZipXML(archive *zip.Reader, name string, v interface{}) error {
+	f, err := archive.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return xml.NewDecoder(f).Decode(v)
+}
+
+// cleanValue trims a metadata value and drops producer junk
+func cleanValue(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if len(s) < 2 {
+		return ""
+	}
+	lower := strings.ToLower(s)
+	for _, junk := range junkValues {
+		if lower == junk {
+			return ""
+		}
+	}
+	for _, junk := range junkFragments {
+		if strings.Contains(lower, junk) {
+			return ""
+		}
+	}
+	return s
+}
+
+func buildMetadata(title string, creators []string, date string) *types.ParsedMetadata {
+	var authors []string
+	for _, c := range creators {
+		if c = cleanValue(c); c != "" {
+			authors = append(authors, c)
+		}
+	}
+
+	var year *uint16
+	if m := yearRegex.FindString(date); m != "" {
+		if y, err := strconv.ParseUint(m, 10, 16); err == nil {
+			v := uint16(y)
+			year = &v
+		}
+	}
+
+	if title == "" && len(authors) == 0 && year == nil {
+		return nil
+	}
+
+	metadata := &types.ParsedMetadata{Title: title, Year: year}
+	if len(authors) > 0 {
+		joined := strings.Join(authors, ", ")
+		metadata.Authors = &joined
+	}
+	return metadata
+}
@@ -0,0 +1,119 @@
+package metadata
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtractPDFInfo(t *testing.T) {
+	tmpDir := t.TempDir()
+	filePath := filepath.Join(tmpDir, "book.pdf")
+	content := "%PDF-1.4\n1 0 obj\n<< /Title (Sheaf Theory \\(Revised\\)) /Author (B. R. Tennison) >>\nendobj\n" +
+		"trailer\n<< /Size 2 /Info 1 0 R >>\n"
+	assert.NoError(t, os.WriteFile(filePath, []byte(content), 0644))
+
+	metadata, err := Extract(filePath, ".pdf")
+	assert.NoError(t, err)
+	assert.NotNil(t, metadata)
+	assert.Equal(t, "Sheaf Theory (Revised)", metadata.Title)
+	assert.NotNil(t, metadata.Authors)
+	assert.Equal(t, "B. R. Tennison", *metadata.Authors)
+}
+
+func TestExtractPDFJunkAndHex(t *testing.T) {
+	tmpDir := t.TempDir()
+	filePath := filepath.Join(tmpDir, "book.pdf")
+	// UTF-16BE hex title "Ab", producer junk author
+	content := "%PDF-1.4\n3 0 obj\n<< /Title <FEFF00410062> /Author (Microsoft Word - draft.docx) >>\nendobj\n" +
+		"trailer\n<< /Size 4 /Info 3 0 R >>\n"
+	assert.NoError(t, os.WriteFile(filePath, []byte(content), 0644))
+
+	metadata, err := Extract(filePath, ".pdf")
+	assert.NoError(t, err)
+	assert.NotNil(t, metadata)
+	assert.Equal(t, "Ab", metadata.Title)
+	assert.Nil(t, metadata.Authors)
+}
+
+func TestExtractPDFWithoutInfo(t *testing.T) {
+	tmpDir := t.TempDir()
+	filePath := filepath.Join(tmpDir, "book.pdf")
+	assert.NoError(t, os.WriteFile(filePath, []byte("%PDF-1.4\nno info here"), 0644))
+
+	metadata, err := Extract(filePath, ".pdf")
+	assert.NoError(t, err)
+	assert.Nil(t, metadata)
+}
+
+func TestExtractPDFIgnoresOutlineTitles(t *testing.T) {
+	tmpDir := t.TempDir()
+	filePath := filepath.Join(tmpDir, "book.pdf")
+	// The bookmark comes after the Info dictionary and the trailer comes last
+	content := "%PDF-1.4\n" +
+		"1 0 obj\n<< /Title (Real Title) /Author (Jane Doe) >>\nendobj\n" +
+		"11 0 obj\n<< /Title (Chapter 3) /Parent 10 0 R /Dest [4 0 R /Fit] >>\nendobj\n" +
+		"trailer\n<< /Size 12 /Info 1 0 R >>\n"
+	assert.NoError(t, os.WriteFile(filePath, []byte(content), 0644))
+
+	metadata, err := Extract(filePath, ".pdf")
+	assert.NoError(t, err)
+	assert.NotNil(t, metadata)
+	assert.Equal(t, "Real Title", metadata.Title)
+	assert.Equal(t, "Jane Doe", *metadata.Authors)
+
+	// Without an Info dictionary a bookmark title is not metadata
+	content = "%PDF-1.4\n11 0 obj\n<< /Title (Chapter 3) >>\nendobj\ntrailer\n<< /Size 12 >>\n"
+	assert.NoError(t, os.WriteFile(filePath, []byte(content), 0644))
+	metadata, err = Extract(filePath, ".pdf")
+	assert.NoError(t, err)
+	assert.Nil(t, metadata)
+}
+
+func TestExtractEPUB(t *testing.T) {
+	tmpDir := t.TempDir()
+	filePath := filepath.Join(tmpDir, "book.epub")
+
+	f, err := os.Create(filePath)
+	assert.NoError(t, err)
+	w := zip.NewWriter(f)
+	files := map[string]string{
+		"META-INF/container.xml": `<?xml version="1.0"?>
+<container xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+  <rootfiles><rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/></rootfiles>
+</container>`,
+		"OEBPS/content.opf": `<?xml version="1.0"?>
+<package xmlns="http://www.idpf.org/2007/opf" xmlns:dc="http://purl.org/dc/elements/1.1/">
+  <metadata>
+    <dc:title>Algebraic Curves</dc:title>
+    <dc:creator>Ernst Kunz</dc:creator>
+    <dc:creator>Richard G. Belshoff</dc:creator>
+    <dc:date>2005-06-01</dc:date>
+  </metadata>
+</package>`,
+	}
+	for name, body := range files {
+		fw, err := w.Create(name)
+		assert.NoError(t, err)
+		_, err = fw.Write([]byte(body))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, w.Close())
+	assert.NoError(t, f.Close())
+
+	metadata, err := Extract(filePath, ".epub")
+	assert.NoError(t, err)
+	assert.NotNil(t, metadata)
+	assert.Equal(t, "Algebraic Curves", metadata.Title)
+	assert.Equal(t, "Ernst Kunz, Richard G. Belshoff", *metadata.Authors)
+	assert.Equal(t, uint16(2005), *metadata.Year)
+}
+
+func TestExtractUnsupportedExtension(t *testing.T) {
+	metadata, err := Extract("notes.txt", ".txt")
+	assert.NoError(t, err)
+	assert.Nil(t, metadata)
+}
@@ -2,6 +2,7 @@ package normalizer
 
 import (
 	"fmt"
+	"math"
 	"path/filepath"
 	"regexp"
 	"strconv"
@@ -43,12 +44,12 @@ func NormalizeFiles(files []*types.FileInfo) ([]*types.FileInfo, error) {
 			continue
 		}
 
-		metadata, err := parseFilename(file.OriginalName, file.Extension)
-		if err != nil {
-			return nil, fmt.Errorf("failed to parse filename %s: %w", file.OriginalName, err)
-		}
+		metadata, reasons := parseFilenameWithReasons(file.OriginalName, file.Extension)
 
 		newName := generateNewFilename(metadata, file.Extension)
+		if newName == file.OriginalName {
+			reasons = []types.ReasonCode{types.ReasonAlreadyNormalized}
+		}
 
 		// Update file info
 		// We need to create a copy or modify the pointer if it's mutable.
@@ -60,47 +61,79 @@ func NormalizeFiles(files []*types.FileInfo) ([]*types.FileInfo, error) {
 
 		file.NewName = &newName
 		file.NewPath = filepathJoin(filepath.Dir(file.OriginalPath), newName)
+		file.Confidence = scoreConfidence(file.OriginalName, newName, metadata)
+		file.Reasons = reasons
 		result[i] = file
 	}
 
 	return result, nil
 }
 
+// ParseFilename parses a filename into its author, title and year components
+func ParseFilename(filename, extension string) (types.ParsedMetadata, error) {
+	return parseFilename(filename, extension)
+}
+
+// GenerateFilename builds the normalized "Author - Title (Year).ext" filename from metadata
+func GenerateFilename(metadata types.ParsedMetadata, extension string) string {
+	return generateNewFilename(metadata, extension)
+}
+
 // parseFilename parses a filename into metadata components
 func parseFilename(filename, extension string) (types.ParsedMetadata, error) {
+	metadata, _ := parseFilenameWithReasons(filename, extension)
+	return metadata, nil
+}
+
+// parseFilenameWithReasons parses a filename and records which steps changed it
+func parseFilenameWithReasons(filename, extension string) (types.ParsedMetadata, []types.ReasonCode) {
+	var reasons []types.ReasonCode
+	step := func(code types.ReasonCode, before, after string) string {
+		if after != before {
+			reasons = append(reasons, code)
+		}
+		return after
+	}
+
 	// Step 1: Remove extension
 	base := filename
-	base = strings.TrimSuffix(base, ".download")
+	base = step(types.ReasonDownloadSuffixRemoved, base, strings.TrimSuffix(base, ".download"))
 	base = strings.TrimSuffix(base, extension)
 	base = strings.TrimSpace(base)
 
 	// Step 2: Remove series prefixes (must be early)
-	base = removeSeriesPrefixes(base)
+	base = step(types.ReasonSeriesPrefixRemoved, base, removeSeriesPrefixes(base))
 
 	// Step 3: Remove ALL bracketed annotations
-	base = bracketRegex.ReplaceAllString(base, "")
+	base = step(types.ReasonBracketsRemoved, base, bracketRegex.ReplaceAllString(base, ""))
 
 	// Step 4: Clean noise sources (Z-Library, etc.)
 	// MUST happen BEFORE author parsing
-	base = cleanNoiseSources(base)
+	base = step(types.ReasonNoiseStripped, base, cleanNoiseSources(base))
 
 	// Step 5: Remove duplicate markers: -2, -3, (1), (2)
-	base = removeDuplicateMarkers(base)
+	base = step(types.ReasonDuplicateMarkerRemoved, base, removeDuplicateMarkers(base))
 
 	// Step 6: Extract year FIRST
 	year := extractYear(base)
+	if year != nil {
+		reasons = append(reasons, types.ReasonYearExtracted)
+	}
 
 	// Step 7: Remove parentheticals
-	base = cleanParentheticals(base, year)
+	base = step(types.ReasonParentheticalsRemoved, base, cleanParentheticals(base, year))
 
 	// Step 8: Parse author and title
 	authors, title := smartParseAuthorTitle(base)
+	if authors != nil {
+		reasons = append(reasons, types.ReasonAuthorExtracted)
+	}
 
 	return types.ParsedMetadata{
 		Authors: authors,
 		Title:   title,
 		Year:    year,
-	}, nil
+	}, reasons
 }
 
 func removeSeriesPrefixes(s string) string {
@@ -252,8 +285,31 @@ func cleanParentheticals(s string, year *uint16) string {
 	return strings.TrimSpace(result)
 }
 
+// splitCandidate is one possible author/title split produced by a parsing pattern
+type splitCandidate struct {
+	authors *string
+	title   string
+}
+
 func smartParseAuthorTitle(s string) (*string, string) {
+	candidates := splitCandidates(s)
+
+	// Pattern 5: No clear author
+	if len(candidates) == 0 {
+		return nil, cleanTitle(s)
+	}
+
+	best := candidates[0]
+	if len(candidates) > 1 {
+		best = chooseSplit(candidates)
+	}
+	return best.authors, best.title
+}
+
+// splitCandidates collects the splits of every pattern that matches, in priority order
+func splitCandidates(s string) []splitCandidate {
 	s = strings.TrimSpace(s)
+	var candidates []splitCandidate
 
 	// Pattern 1: "Title (Author)"
 	if matches := trailingAuthorRegex.FindStringSubmatch(s); matches != nil {
@@ -263,7 +319,7 @@ func smartParseAuthorTitle(s string) (*string, string) {
 		if isLikelyAuthor(authorPart) && !isPublisherOrSeriesInfo("("+authorPart+")") {
 			cleanAuth := cleanAuthorName(authorPart)
 			cleanTitl := cleanTitle(titlePart)
-			return &cleanAuth, cleanTitl
+			candidates = append(candidates, splitCandidate{&cleanAuth, cleanTitl})
 		}
 	}
 
@@ -275,7 +331,7 @@ func smartParseAuthorTitle(s string) (*string, string) {
 		if isLikelyAuthor(authorPart) && titlePart != "" {
 			cleanAuth := cleanAuthorName(authorPart)
 			cleanTitl := cleanTitle(titlePart)
-			return &cleanAuth, cleanTitl
+			candidates = append(candidates, splitCandidate{&cleanAuth, cleanTitl})
 		}
 	}
 
@@ -288,7 +344,7 @@ func smartParseAuthorTitle(s string) (*string, string) {
 		if isLikelyAuthor(author1) && isLikelyAuthor(author2) {
 			authors := fmt.Sprintf("%s, %s", cleanAuthorName(author1), cleanAuthorName(author2))
 			cleanTitl := cleanTitle(titlePart)
-			return &authors, cleanTitl
+			candidates = append(candidates, splitCandidate{&authors, cleanTitl})
 		}
 	}
 
@@ -300,12 +356,11 @@ func smartParseAuthorTitle(s string) (*string, string) {
 		if isLikelyAuthor(authorPart) && !isPublisherOrSeriesInfo(authorPart) {
 			cleanAuth := cleanAuthorName(authorPart)
 			cleanTitl := cleanTitle(titlePart)
-			return &cleanAuth, cleanTitl
+			candidates = append(candidates, splitCandidate{&cleanAuth, cleanTitl})
 		}
 	}
 
-	// Pattern 5: No clear author
-	return nil, cleanTitle(s)
+	return candidates
 }
 
 func isLikelyAuthor(s string) bool {
@@ -554,6 +609,31 @@ func generateNewFilename(metadata types.ParsedMetadata, extension string) string
 	return result.String()
 }
 
+// scoreConfidence estimates how trustworthy a generated filename is, from 0 to 1
+func scoreConfidence(originalName, newName string, metadata types.ParsedMetadata) float64 {
+	if newName == originalName {
+		return 1
+	}
+	if metadata.Title == "" {
+		return 0
+	}
+
+	score := 0.4
+	if metadata.Authors != nil {
+		score += 0.4
+	}
+	if metadata.Year != nil {
+		score += 0.1
+	}
+
+	// Dropping most of the original name means more of it was guesswork
+	if float64(len(newName)) < 0.3*float64(len(originalName)) {
+		score -= 0.2
+	}
+
+	return math.Max(0, math.Min(1, score))
+}
+
 func filepathJoin(dir, file string) string {
 	return filepath.Join(dir, file)
 }
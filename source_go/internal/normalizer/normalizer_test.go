@@ -4,6 +4,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/ebook-renamer/go/internal/types"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -277,3 +278,46 @@ func TestGraduateTextsSeriesRemoval(t *testing.T) {
 	assert.Equal(t, uint16(1978), *metadata.Year)
 	assert.NotContains(t, metadata.Title, "Graduate Texts")
 }
+
+func TestSplitCandidatesKeepsPatternOrder(t *testing.T) {
+	candidates := splitCandidates("Sheldon Axler - Topology (Lectures on Manifolds)")
+	assert.Len(t, candidates, 2)
+	assert.Equal(t, "Lectures on Manifolds", *candidates[0].authors)
+	assert.Equal(t, "Sheldon Axler", *candidates[1].authors)
+
+	assert.Empty(t, splitCandidates("just a lowercase title"))
+}
+
+func TestScoreConfidence(t *testing.T) {
+	author := "John Smith"
+	year := uint16(2020)
+
+	full := types.ParsedMetadata{Authors: &author, Title: "Book", Year: &year}
+	assert.InDelta(t, 0.9, scoreConfidence("John Smith - Book (2020, Press).pdf", "John Smith - Book (2020).pdf", full), 1e-9)
+
+	authorOnly := types.ParsedMetadata{Authors: &author, Title: "Book"}
+	assert.InDelta(t, 0.8, scoreConfidence("John_Smith - Book.pdf", "John Smith - Book.pdf", authorOnly), 1e-9)
+
+	titleOnly := types.ParsedMetadata{Title: "Book"}
+	assert.InDelta(t, 0.4, scoreConfidence("Book_.pdf", "Book.pdf", titleOnly), 1e-9)
+
+	// Unchanged names are always safe, heavy stripping is penalized
+	assert.Equal(t, 1.0, scoreConfidence("Book.pdf", "Book.pdf", titleOnly))
+	assert.InDelta(t, 0.2, scoreConfidence("Book [some very long annotation that gets stripped].pdf", "Book.pdf", titleOnly), 1e-9)
+}
+
+func TestNormalizeFilesRecordsReasons(t *testing.T) {
+	files := []*types.FileInfo{
+		{OriginalPath: "/lib/John Smith - Book (2020) (Z-Library).pdf", OriginalName: "John Smith - Book (2020) (Z-Library).pdf", Extension: ".pdf"},
+		{OriginalPath: "/lib/John Smith - Book (2020).pdf", OriginalName: "John Smith - Book (2020).pdf", Extension: ".pdf"},
+	}
+
+	normalized, err := NormalizeFiles(files)
+	assert.NoError(t, err)
+	assert.Equal(t, "John Smith - Book (2020).pdf", *normalized[0].NewName)
+	assert.Contains(t, normalized[0].Reasons, types.ReasonNoiseStripped)
+	assert.Contains(t, normalized[0].Reasons, types.ReasonYearExtracted)
+	assert.Contains(t, normalized[0].Reasons, types.ReasonAuthorExtracted)
+
+	assert.Equal(t, []types.ReasonCode{types.ReasonAlreadyNormalized}, normalized[1].Reasons)
+}
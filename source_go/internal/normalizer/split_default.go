@@ -0,0 +1,10 @@
+//go:build !mlsplit
+
+package normalizer
+
+// chooseSplit picks between competing author/title splits.
+// The default build trusts pattern priority so output stays identical across implementations;
+// build with -tags mlsplit to score candidates with the token classifier instead.
+func chooseSplit(candidates []splitCandidate) splitCandidate {
+	return candidates[0]
+}
@@ -0,0 +1,124 @@
+//go:build mlsplit
+
+package normalizer
+
+import (
+	"math"
+	"strings"
+	"unicode"
+)
+
+// splitFeatures are the token features the classifier looks at for one candidate
+type splitFeatures struct {
+	authorTokens      float64
+	capitalizedRatio  float64
+	initials          float64
+	authorStopwords   float64
+	authorTitleWords  float64
+	authorHasDigit    float64
+	titleTokens       float64
+	authorLongerTitle float64
+}
+
+// Weights of the logistic classifier, tuned by hand against the normalizer test corpus
+var (
+	splitBias    = -0.5
+	splitWeights = splitFeatures{
+		authorTokens:      -0.35,
+		capitalizedRatio:  2.5,
+		initials:          0.8,
+		authorStopwords:   -1.8,
+		authorTitleWords:  -2.2,
+		authorHasDigit:    -1.5,
+		titleTokens:       0.15,
+		authorLongerTitle: -1.0,
+	}
+)
+
+// Lowercase function words are common in titles and rare in names
+var splitStopwords = map[string]bool{
+	"a": true, "an": true, "and": true, "the": true, "of": true, "in": true,
+	"on": true, "to": true, "for": true, "with": true, "from": true, "by": true,
+}
+
+// Words that mark a phrase as a book title rather than a person
+var splitTitleWords = map[string]bool{
+	"introduction": true, "theory": true, "handbook": true, "guide": true,
+	"principles": true, "elements": true, "lectures": true, "notes": true,
+	"course": true, "foundations": true, "methods": true, "analysis": true,
+}
+
+// chooseSplit scores each competing split with the token classifier and keeps the
+// best one; ties fall back to pattern priority.
+func chooseSplit(candidates []splitCandidate) splitCandidate {
+	best := candidates[0]
+	bestScore := scoreSplit(best)
+	for _, c := range candidates[1:] {
+		if score := scoreSplit(c); score > bestScore {
+			best, bestScore = c, score
+		}
+	}
+	return best
+}
+
+// scoreSplit returns the probability that the candidate assigns authors and title correctly
+func scoreSplit(c splitCandidate) float64 {
+	if c.authors == nil {
+		return 0
+	}
+	f := extractSplitFeatures(*c.authors, c.title)
+	w := splitWeights
+
+	z := splitBias +
+		w.authorTokens*f.authorTokens +
+		w.capitalizedRatio*f.capitalizedRatio +
+		w.initials*f.initials +
+		w.authorStopwords*f.authorStopwords +
+		w.authorTitleWords*f.authorTitleWords +
+		w.authorHasDigit*f.authorHasDigit +
+		w.titleTokens*f.titleTokens +
+		w.authorLongerTitle*f.authorLongerTitle
+
+	return 1 / (1 + math.Exp(-z))
+}
+
+func extractSplitFeatures(authors, title string) splitFeatures {
+	var f splitFeatures
+
+	authorWords := strings.Fields(strings.ReplaceAll(authors, ",", " "))
+	titleWords := strings.Fields(title)
+	f.authorTokens = float64(len(authorWords))
+	f.titleTokens = math.Min(float64(len(titleWords)), 8)
+	if len(authorWords) > len(titleWords) {
+		f.authorLongerTitle = 1
+	}
+
+	capitalized := 0
+	for _, word := range authorWords {
+		lower := strings.ToLower(word)
+		if splitStopwords[lower] {
+			f.authorStopwords++
+		}
+		if splitTitleWords[lower] {
+			f.authorTitleWords++
+		}
+
+		runes := []rune(word)
+		if unicode.IsUpper(runes[0]) || (unicode.IsLetter(runes[0]) && runes[0] > 127) {
+			capitalized++
+		}
+		if len(runes) == 2 && unicode.IsUpper(runes[0]) && runes[1] == '.' {
+			f.initials++
+		}
+		for _, r := range runes {
+			if unicode.IsDigit(r) {
+				f.authorHasDigit = 1
+			}
+		}
+	}
+	if len(authorWords) > 0 {
+		f.capitalizedRatio = float64(capitalized) / float64(len(authorWords))
+	}
+
+	return f
+}
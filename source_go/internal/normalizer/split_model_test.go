@@ -0,0 +1,27 @@
+//go:build mlsplit
+
+package normalizer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChooseSplitPrefersNameLikeAuthor(t *testing.T) {
+	// Pattern priority would take "Lectures on Manifolds" as the trailing author
+	authors, title := smartParseAuthorTitle("Sheldon Axler - Topology (Lectures on Manifolds)")
+	assert.NotNil(t, authors)
+	assert.Equal(t, "Sheldon Axler", *authors)
+	assert.Equal(t, "Topology (Lectures on Manifolds)", title)
+}
+
+func TestScoreSplit(t *testing.T) {
+	name := "B. R. Tennison"
+	phrase := "Introduction to the Theory"
+
+	good := scoreSplit(splitCandidate{&name, "Sheaf Theory"})
+	bad := scoreSplit(splitCandidate{&phrase, "B. R. Tennison"})
+	assert.Greater(t, good, bad)
+	assert.Zero(t, scoreSplit(splitCandidate{nil, "Sheaf Theory"}))
+}
@@ -0,0 +1,224 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/ebook-renamer/go/internal/index"
+)
+
+// Series describes the volumes of one series found in the library
+type Series struct {
+	Name     string   `json:"name"`
+	Authors  []string `json:"authors"`
+	Have     []int    `json:"have"`
+	Missing  []int    `json:"missing"`
+	Complete bool     `json:"complete"`
+}
+
+// Cluster is a group of authors connected by co-written books
+type Cluster struct {
+	Authors []string `json:"authors"`
+	Books   int      `json:"books"`
+}
+
+// Report summarizes series completeness and co-author clusters of a library
+type Report struct {
+	Books    int       `json:"books"`
+	Series   []Series  `json:"series"`
+	Clusters []Cluster `json:"coauthor_clusters"`
+}
+
+// Build creates the report from a library index
+func Build(idx *index.Index) *Report {
+	return &Report{
+		Books:    len(idx.Records),
+		Series:   buildSeries(idx.Records),
+		Clusters: buildClusters(idx.Records),
+	}
+}
+
+// key folds case and spacing so "Art of Programming" and "art of  programming" group together
+func key(s string) string {
+	return strings.ToLower(strings.Join(strings.Fields(s), " "))
+}
+
+func buildSeries(records []index.Record) []Series {
+	type entry struct {
+		series  *Series
+		volumes map[int]bool
+		authors map[string]bool
+	}
+	entries := make(map[string]*entry)
+	var order []string
+
+	for _, r := range records {
+		if r.Series == "" {
+			continue
+		}
+		k := key(r.Series)
+		e, ok := entries[k]
+		if !ok {
+			e = &entry{series: &Series{Name: r.Series, Authors: []string{}}, volumes: map[int]bool{}, authors: map[string]bool{}}
+			entries[k] = e
+			order = append(order, k)
+		}
+		e.volumes[r.SeriesIndex] = true
+		if r.Authors != nil {
+			for _, a := range index.SplitAuthors(*r.Authors) {
+				if !e.authors[key(a)] {
+					e.authors[key(a)] = true
+					e.series.Authors = append(e.series.Authors, a)
+				}
+			}
+		}
+	}
+
+	result := []Series{}
+	for _, k := range order {
+		e := entries[k]
+		s := e.series
+		s.Have = []int{}
+		s.Missing = []int{}
+		highest := 0
+		for v := range e.volumes {
+			s.Have = append(s.Have, v)
+			highest = max(highest, v)
+		}
+		sort.Ints(s.Have)
+		// Volumes after the highest one owned can't be detected from the library alone
+		for v := 1; v <= highest; v++ {
+			if !e.volumes[v] {
+				s.Missing = append(s.Missing, v)
+			}
+		}
+		s.Complete = len(s.Missing) == 0
+		result = append(result, *s)
+	}
+
+	// Incomplete series first, then by name
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Complete != result[j].Complete {
+			return !result[i].Complete
+		}
+		return key(result[i].Name) < key(result[j].Name)
+	})
+	return result
+}
+
+func buildClusters(records []index.Record) []Cluster {
+	// Union-find over authors, joined by every multi-author book
+	parent := make(map[string]string)
+	display := make(map[string]string)
+	var find func(string) string
+	find = func(a string) string {
+		if parent[a] != a {
+			parent[a] = find(parent[a])
+		}
+		return parent[a]
+	}
+
+	var coauthored [][]string
+	for _, r := range records {
+		if r.Authors == nil {
+			continue
+		}
+		var keys []string
+		for _, name := range index.SplitAuthors(*r.Authors) {
+			k := key(name)
+			if _, ok := parent[k]; !ok {
+				parent[k] = k
+				display[k] = name
+			}
+			keys = append(keys, k)
+		}
+		if len(keys) < 2 {
+			continue
+		}
+		for _, k := range keys[1:] {
+			if ra, rb := find(keys[0]), find(k); ra != rb {
+				parent[rb] = ra
+			}
+		}
+		coauthored = append(coauthored, keys)
+	}
+
+	members := make(map[string][]string)
+	for k := range parent {
+		root := find(k)
+		members[root] = append(members[root], k)
+	}
+	books := make(map[string]int)
+	for _, keys := range coauthored {
+		books[find(keys[0])]++
+	}
+
+	result := []Cluster{}
+	for root, count := range books {
+		cluster := Cluster{Books: count}
+		for _, k := range members[root] {
+			cluster.Authors = append(cluster.Authors, display[k])
+		}
+		sort.Slice(cluster.Authors, func(i, j int) bool {
+			return key(cluster.Authors[i]) < key(cluster.Authors[j])
+		})
+		result = append(result, cluster)
+	}
+
+	// Largest clusters first
+	sort.Slice(result, func(i, j int) bool {
+		if len(result[i].Authors) != len(result[j].Authors) {
+			return len(result[i].Authors) > len(result[j].Authors)
+		}
+		if result[i].Books != result[j].Books {
+			return result[i].Books > result[j].Books
+		}
+		return key(result[i].Authors[0]) < key(result[j].Authors[0])
+	})
+	return result
+}
+
+// WriteText writes the report as readable sections
+func (r *Report) WriteText(w io.Writer) {
+	incomplete := 0
+	for _, s := range r.Series {
+		if !s.Complete {
+			incomplete++
+		}
+	}
+
+	fmt.Fprintf(w, "📚 系列完整性 (%d 个系列, %d 个不完整):\n", len(r.Series), incomplete)
+	fmt.Fprintln(w, "----------------------------------------")
+	if incomplete == 0 {
+		fmt.Fprintln(w, "  (无缺卷)")
+	}
+	for _, s := range r.Series {
+		if s.Complete {
+			continue
+		}
+		fmt.Fprintf(w, "  %s", s.Name)
+		if len(s.Authors) > 0 {
+			fmt.Fprintf(w, " — %s", strings.Join(s.Authors, ", "))
+		}
+		fmt.Fprintf(w, "\n    已有: %s\n    缺少: %s\n", joinInts(s.Have), joinInts(s.Missing))
+	}
+
+	fmt.Fprintf(w, "\n👥 合著者群组 (%d 个):\n", len(r.Clusters))
+	fmt.Fprintln(w, "----------------------------------------")
+	if len(r.Clusters) == 0 {
+		fmt.Fprintln(w, "  (无合著书籍)")
+	}
+	for _, c := range r.Clusters {
+		fmt.Fprintf(w, "  %s (%d 本合著)\n", strings.Join(c.Authors, ", "), c.Books)
+	}
+}
+
+func joinInts(nums []int) string {
+	parts := make([]string, len(nums))
+	for i, n := range nums {
+		parts[i] = fmt.Sprint(n)
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,65 @@
+package report
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ebook-renamer/go/internal/index"
+	"github.com/stretchr/testify/assert"
+)
+
+func record(authors, title string) index.Record {
+	r := index.Record{Path: title + ".pdf", Title: title}
+	if authors != "" {
+		r.Authors = &authors
+	}
+	if series, n, ok := index.DetectSeries(title); ok {
+		r.Series, r.SeriesIndex = series, n
+	}
+	return r
+}
+
+func TestSeriesGaps(t *testing.T) {
+	idx := &index.Index{Records: []index.Record{
+		record("Donald Knuth", "The Art of Computer Programming, Vol. 1"),
+		record("Donald Knuth", "The Art of Computer Programming, Vol. 4"),
+		record("Isaac Asimov", "Foundation Book 1"),
+		record("Isaac Asimov", "Foundation Book 2"),
+		record("", "Standalone Novel"),
+	}}
+
+	r := Build(idx)
+	assert.Equal(t, 5, r.Books)
+	assert.Len(t, r.Series, 2)
+
+	taocp := r.Series[0]
+	assert.Equal(t, "The Art of Computer Programming", taocp.Name)
+	assert.Equal(t, []string{"Donald Knuth"}, taocp.Authors)
+	assert.Equal(t, []int{1, 4}, taocp.Have)
+	assert.Equal(t, []int{2, 3}, taocp.Missing)
+	assert.False(t, taocp.Complete)
+
+	assert.Equal(t, "Foundation", r.Series[1].Name)
+	assert.True(t, r.Series[1].Complete)
+	assert.Empty(t, r.Series[1].Missing)
+}
+
+func TestCoauthorClusters(t *testing.T) {
+	idx := &index.Index{Records: []index.Record{
+		record("Ronald Graham, Donald Knuth", "Concrete Mathematics"),
+		record("Donald Knuth; Oren Patashnik", "Concrete Mathematics Notes"),
+		record("Harold Abelson & Gerald Sussman", "SICP"),
+		record("Knuth, Donald", "Surreal Numbers"),
+		record("Solo Writer", "Alone"),
+	}}
+
+	r := Build(idx)
+	assert.Equal(t, []Cluster{
+		{Authors: []string{"Donald Knuth", "Oren Patashnik", "Ronald Graham"}, Books: 2},
+		{Authors: []string{"Gerald Sussman", "Harold Abelson"}, Books: 1},
+	}, r.Clusters)
+
+	var buf bytes.Buffer
+	r.WriteText(&buf)
+	assert.Contains(t, buf.String(), "Donald Knuth, Oren Patashnik, Ronald Graham (2 本合著)")
+}
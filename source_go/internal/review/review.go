@@ -0,0 +1,114 @@
+package review
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"path/filepath"
+	"strings"
+
+	"github.com/ebook-renamer/go/internal/types"
+)
+
+// OperationKind identifies the kind of a planned operation
+type OperationKind string
+
+const (
+	OperationRename          OperationKind = "rename"
+	OperationDeleteDuplicate OperationKind = "delete_duplicate"
+	OperationDeleteFile      OperationKind = "delete"
+)
+
+// Operation represents a single planned change presented for review
+type Operation struct {
+	Kind   OperationKind
+	Path   string
+	Target string
+}
+
+// String renders the operation the same way the dry-run output does
+func (op Operation) String() string {
+	switch op.Kind {
+	case OperationRename:
+		return fmt.Sprintf("RENAME: %s -> %s", filepath.Base(op.Path), filepath.Base(op.Target))
+	case OperationDeleteDuplicate:
+		return fmt.Sprintf("DELETE DUPLICATE: %s (keeping %s)", op.Path, op.Target)
+	default:
+		return fmt.Sprintf("DELETE: %s", op.Path)
+	}
+}
+
+// PlannedOperations collects every operation that executing the plan would perform
+func PlannedOperations(cleanFiles []*types.FileInfo, duplicateGroups [][]string, filesToDelete []string, noDelete bool) []Operation {
+	var ops []Operation
+
+	for _, fileInfo := range cleanFiles {
+		// Renames that keep the current name are not real changes
+		if fileInfo.NewName != nil && *fileInfo.NewName != fileInfo.OriginalName {
+			ops = append(ops, Operation{Kind: OperationRename, Path: fileInfo.OriginalPath, Target: fileInfo.NewPath})
+		}
+	}
+
+	if !noDelete {
+		for _, group := range duplicateGroups {
+			if len(group) > 1 {
+				for _, path := range group[1:] {
+					ops = append(ops, Operation{Kind: OperationDeleteDuplicate, Path: path, Target: group[0]})
+				}
+			}
+		}
+	}
+
+	for _, path := range filesToDelete {
+		ops = append(ops, Operation{Kind: OperationDeleteFile, Path: path})
+	}
+
+	return ops
+}
+
+// Sample picks n operations uniformly at random, preserving their original order.
+// If n covers every operation, all operations are returned.
+func Sample(ops []Operation, n int, rng *rand.Rand) []Operation {
+	if n >= len(ops) {
+		return ops
+	}
+	if n <= 0 {
+		return nil
+	}
+
+	picked := make(map[int]bool, n)
+	for _, idx := range rng.Perm(len(ops))[:n] {
+		picked[idx] = true
+	}
+
+	sample := make([]Operation, 0, n)
+	for i, op := range ops {
+		if picked[i] {
+			sample = append(sample, op)
+		}
+	}
+	return sample
+}
+
+// Confirm shows the sampled operations and asks whether the whole plan may be applied.
+// Anything other than an explicit "y" or "yes" is treated as a rejection.
+func Confirm(in io.Reader, out io.Writer, sample []Operation, total int) (bool, error) {
+	fmt.Fprintf(out, "\n=== REVIEW SAMPLE (%d of %d operations) ===\n", len(sample), total)
+	for i, op := range sample {
+		fmt.Fprintf(out, "  %3d. %s\n", i+1, op)
+	}
+	fmt.Fprintf(out, "\nApply all %d operations? [y/N]: ", total)
+
+	answer, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return false, fmt.Errorf("failed to read answer: %w", err)
+	}
+
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true, nil
+	default:
+		return false, nil
+	}
+}
@@ -0,0 +1,79 @@
+package review
+
+import (
+	"bytes"
+	"math/rand/v2"
+	"strings"
+	"testing"
+
+	"github.com/ebook-renamer/go/internal/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func sampleOps(n int) []Operation {
+	var ops []Operation
+	for i := 0; i < n; i++ {
+		ops = append(ops, Operation{Kind: OperationDeleteFile, Path: string(rune('a' + i))})
+	}
+	return ops
+}
+
+func TestPlannedOperations(t *testing.T) {
+	newName := "Author - Title.pdf"
+	cleanFiles := []*types.FileInfo{
+		{OriginalPath: "/lib/raw.pdf", NewName: &newName, NewPath: "/lib/Author - Title.pdf"},
+		{OriginalPath: "/lib/untouched.pdf"},
+	}
+	groups := [][]string{{"/lib/keep.pdf", "/lib/dup1.pdf", "/lib/dup2.pdf"}}
+	toDelete := []string{"/lib/broken.pdf"}
+
+	ops := PlannedOperations(cleanFiles, groups, toDelete, false)
+	assert.Len(t, ops, 4)
+	assert.Equal(t, OperationRename, ops[0].Kind)
+	assert.Equal(t, OperationDeleteDuplicate, ops[1].Kind)
+	assert.Equal(t, "/lib/keep.pdf", ops[1].Target)
+	assert.Equal(t, OperationDeleteFile, ops[3].Kind)
+
+	// Duplicates are left alone in no-delete mode
+	ops = PlannedOperations(cleanFiles, groups, toDelete, true)
+	assert.Len(t, ops, 2)
+}
+
+func TestSample(t *testing.T) {
+	ops := sampleOps(10)
+	rng := rand.New(rand.NewPCG(1, 2))
+
+	sample := Sample(ops, 3, rng)
+	assert.Len(t, sample, 3)
+
+	// Sample keeps the original relative order
+	for i := 1; i < len(sample); i++ {
+		assert.Less(t, sample[i-1].Path, sample[i].Path)
+	}
+
+	assert.Len(t, Sample(ops, 20, rng), 10)
+	assert.Empty(t, Sample(ops, 0, rng))
+}
+
+func TestConfirm(t *testing.T) {
+	ops := sampleOps(2)
+
+	testCases := []struct {
+		input    string
+		expected bool
+	}{
+		{"y\n", true},
+		{"YES\n", true},
+		{"n\n", false},
+		{"\n", false},
+		{"", false},
+	}
+
+	for _, tc := range testCases {
+		var out bytes.Buffer
+		accepted, err := Confirm(strings.NewReader(tc.input), &out, ops, 5)
+		assert.NoError(t, err)
+		assert.Equal(t, tc.expected, accepted, "Input: %q", tc.input)
+		assert.Contains(t, out.String(), "2 of 5 operations")
+	}
+}
@@ -0,0 +1,158 @@
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+const (
+	fileVersion   = 1
+	kdfIterations = 600000
+	saltSize      = 16
+	keySize       = 32
+)
+
+// encryptedFile is the on-disk format: the secrets map, JSON encoded and sealed with
+// AES-256-GCM under a key derived from the passphrase with PBKDF2-SHA256
+type encryptedFile struct {
+	Version    int    `json:"version"`
+	Iterations int    `json:"iterations"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// FileStore keeps secrets in an encrypted file
+type FileStore struct {
+	path       string
+	passphrase string
+	secrets    map[string]string
+}
+
+// NewFileStore opens or initializes the encrypted store at path.
+// A wrong passphrase for an existing file is reported as an error.
+func NewFileStore(path, passphrase string) (*FileStore, error) {
+	if passphrase == "" {
+		return nil, fmt.Errorf("empty passphrase")
+	}
+	store := &FileStore{path: path, passphrase: passphrase, secrets: map[string]string{}}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var file encryptedFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("invalid secrets file %s: %w", path, err)
+	}
+	if file.Version > fileVersion {
+		return nil, fmt.Errorf("secrets file %s has unsupported version %d", path, file.Version)
+	}
+
+	gcm, err := newGCM(passphrase, file.Salt, file.Iterations)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := gcm.Open(nil, file.Nonce, file.Ciphertext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decrypt secrets file (wrong passphrase?)")
+	}
+	if err := json.Unmarshal(plaintext, &store.secrets); err != nil {
+		return nil, fmt.Errorf("corrupt secrets file %s: %w", path, err)
+	}
+	return store, nil
+}
+
+func (s *FileStore) Name() string {
+	return "encrypted file " + s.path
+}
+
+func (s *FileStore) Get(key string) (string, error) {
+	value, ok := s.secrets[key]
+	if !ok {
+		return "", ErrNotFound
+	}
+	return value, nil
+}
+
+func (s *FileStore) Set(key, value string) error {
+	s.secrets[key] = value
+	return s.save()
+}
+
+func (s *FileStore) Delete(key string) error {
+	if _, ok := s.secrets[key]; !ok {
+		return ErrNotFound
+	}
+	delete(s.secrets, key)
+	return s.save()
+}
+
+// save re-encrypts the whole store with a fresh salt and nonce and replaces the file atomically
+func (s *FileStore) save() error {
+	plaintext, err := json.Marshal(s.secrets)
+	if err != nil {
+		return err
+	}
+
+	file := encryptedFile{Version: fileVersion, Iterations: kdfIterations, Salt: make([]byte, saltSize)}
+	if _, err := rand.Read(file.Salt); err != nil {
+		return err
+	}
+	gcm, err := newGCM(s.passphrase, file.Salt, file.Iterations)
+	if err != nil {
+		return err
+	}
+	file.Nonce = make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(file.Nonce); err != nil {
+		return err
+	}
+	file.Ciphertext = gcm.Seal(nil, file.Nonce, plaintext, nil)
+
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), ".secrets-*.enc")
+	if err != nil {
+		return err
+	}
+	// CreateTemp makes the file readable by the owner only
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
+
+func newGCM(passphrase string, salt []byte, iterations int) (cipher.AEAD, error) {
+	key, err := pbkdf2.Key(sha256.New, passphrase, salt, iterations, keySize)
+	if err != nil {
+		return nil, fmt.Errorf("key derivation failed: %w", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
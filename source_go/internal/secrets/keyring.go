@@ -0,0 +1,152 @@
+package secrets
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// commandKeyring talks to the OS keyring through its command-line tool:
+// security(1) on macOS and secret-tool(1) from libsecret on Linux
+type commandKeyring struct {
+	tool string
+}
+
+// systemKeyring returns the keyring for this OS, or nil if none is available
+func systemKeyring() Store {
+	switch runtime.GOOS {
+	case "darwin":
+		if path, err := exec.LookPath("security"); err == nil {
+			return &commandKeyring{tool: path}
+		}
+	case "linux", "freebsd", "openbsd":
+		// secret-tool needs a running Secret Service on the session bus
+		if os.Getenv("DBUS_SESSION_BUS_ADDRESS") == "" {
+			return nil
+		}
+		if path, err := exec.LookPath("secret-tool"); err == nil {
+			return &commandKeyring{tool: path}
+		}
+	}
+	return nil
+}
+
+func (k *commandKeyring) Name() string {
+	return "OS keyring"
+}
+
+func (k *commandKeyring) Get(key string) (string, error) {
+	var out []byte
+	var err error
+	if runtime.GOOS == "darwin" {
+		out, err = k.run(nil, "find-generic-password", "-s", Service, "-a", key, "-w")
+	} else {
+		out, err = k.run(nil, "lookup", "service", Service, "account", key)
+	}
+	if isNotFound(err) || (err == nil && len(out) == 0) {
+		return "", ErrNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(out), "\n"), nil
+}
+
+// isNotFound tells a missing item from other keyring failures (locked keyring, denied access, no
+// Secret Service). security(1) exits with errSecItemNotFound (44); secret-tool exits 1 silently.
+func isNotFound(err error) bool {
+	var toolErr *toolError
+	if !errors.As(err, &toolErr) {
+		return false
+	}
+	var exitErr *exec.ExitError
+	if !errors.As(toolErr.err, &exitErr) {
+		return false
+	}
+	if runtime.GOOS == "darwin" {
+		return exitErr.ExitCode() == 44
+	}
+	return exitErr.ExitCode() == 1 && toolErr.stderr == ""
+}
+
+func (k *commandKeyring) Set(key, value string) error {
+	var err error
+	if runtime.GOOS == "darwin" {
+		// security(1) only takes the password as an argument, so the command is fed through
+		// its interactive mode on stdin to keep the value out of the process list
+		command := fmt.Sprintf("add-generic-password -U -s %s -a %s -w %s\n", quoteSecurityArg(Service), quoteSecurityArg(key), quoteSecurityArg(value))
+		var stderr []byte
+		stderr, err = k.runInteractive(command)
+		if err == nil && len(stderr) > 0 {
+			// Interactive mode reports failed commands on stderr but still exits 0
+			err = fmt.Errorf("%s add-generic-password failed: %s", k.tool, strings.TrimSpace(string(stderr)))
+		}
+	} else {
+		_, err = k.run(strings.NewReader(value), "store", "--label", Service+": "+key, "service", Service, "account", key)
+	}
+	return err
+}
+
+func (k *commandKeyring) Delete(key string) error {
+	if _, err := k.Get(key); err != nil {
+		return err
+	}
+	var err error
+	if runtime.GOOS == "darwin" {
+		_, err = k.run(nil, "delete-generic-password", "-s", Service, "-a", key)
+	} else {
+		_, err = k.run(nil, "clear", "service", Service, "account", key)
+	}
+	return err
+}
+
+func (k *commandKeyring) run(stdin *strings.Reader, args ...string) ([]byte, error) {
+	cmd := exec.Command(k.tool, args...)
+	if stdin != nil {
+		cmd.Stdin = stdin
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, &toolError{tool: k.tool, command: args[0], err: err, stderr: strings.TrimSpace(stderr.String())}
+	}
+	return out, nil
+}
+
+// toolError is a failed keyring tool invocation
+type toolError struct {
+	tool    string
+	command string
+	err     error
+	stderr  string
+}
+
+func (e *toolError) Error() string {
+	return fmt.Sprintf("%s %s failed: %v: %s", e.tool, e.command, e.err, e.stderr)
+}
+
+func (e *toolError) Unwrap() error {
+	return e.err
+}
+
+// runInteractive runs commands through "security -i" and returns its stderr
+func (k *commandKeyring) runInteractive(commands string) ([]byte, error) {
+	cmd := exec.Command(k.tool, "-i")
+	cmd.Stdin = strings.NewReader(commands)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if _, err := cmd.Output(); err != nil {
+		return nil, fmt.Errorf("%s -i failed: %w: %s", k.tool, err, strings.TrimSpace(stderr.String()))
+	}
+	return stderr.Bytes(), nil
+}
+
+// quoteSecurityArg quotes an argument for the command line parser of "security -i"
+func quoteSecurityArg(arg string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(arg) + `"`
+}
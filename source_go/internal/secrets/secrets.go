@@ -0,0 +1,73 @@
+package secrets
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Service is the name secrets are filed under in the OS keyring
+const Service = "ebook-renamer"
+
+// Environment variables that control the secrets store
+const (
+	BackendEnv    = "EBOOK_RENAMER_SECRETS_BACKEND"
+	PassphraseEnv = "EBOOK_RENAMER_SECRETS_PASSPHRASE"
+)
+
+// ErrNotFound is returned when a secret does not exist
+var ErrNotFound = errors.New("secret not found")
+
+// Store keeps provider tokens and API keys
+type Store interface {
+	Get(key string) (string, error)
+	Set(key, value string) error
+	Delete(key string) error
+	// Name describes the backend for user-facing messages
+	Name() string
+}
+
+// Open returns the OS keyring if one is usable, otherwise the encrypted file store.
+// Setting EBOOK_RENAMER_SECRETS_BACKEND to "keyring" or "file" forces a backend.
+// passphrase is asked for only when the file store needs one and the environment does not provide it.
+func Open(passphrase func() (string, error)) (Store, error) {
+	backend := os.Getenv(BackendEnv)
+
+	if backend == "" || backend == "keyring" {
+		if keyring := systemKeyring(); keyring != nil {
+			return keyring, nil
+		}
+		if backend == "keyring" {
+			return nil, fmt.Errorf("no OS keyring available")
+		}
+	} else if backend != "file" {
+		return nil, fmt.Errorf("unknown secrets backend %q (use keyring or file)", backend)
+	}
+
+	path, err := DefaultFilePath()
+	if err != nil {
+		return nil, err
+	}
+	pass := os.Getenv(PassphraseEnv)
+	if pass == "" {
+		if passphrase == nil {
+			return nil, fmt.Errorf("encrypted secrets file needs a passphrase (set %s)", PassphraseEnv)
+		}
+		if pass, err = passphrase(); err != nil {
+			return nil, err
+		}
+	}
+	return NewFileStore(path, pass)
+}
+
+// DefaultFilePath returns where the encrypted fallback store lives.
+// Secrets are per user and deliberately not part of the library state, so they do not
+// travel with a portable library.
+func DefaultFilePath() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("no user config directory: %w", err)
+	}
+	return filepath.Join(configDir, "ebook-renamer", "secrets.enc"), nil
+}
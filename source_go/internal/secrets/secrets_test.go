@@ -0,0 +1,101 @@
+package secrets
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileStoreRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "secrets.enc")
+
+	store, err := NewFileStore(path, "correct horse")
+	assert.NoError(t, err)
+	assert.NoError(t, store.Set("cloud.dropbox.token", "sl.abc123"))
+	assert.NoError(t, store.Set("metadata.arxiv.api_key", "k-42"))
+
+	// The token must not be readable from disk
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(data), "sl.abc123")
+	info, err := os.Stat(path)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	reopened, err := NewFileStore(path, "correct horse")
+	assert.NoError(t, err)
+	value, err := reopened.Get("cloud.dropbox.token")
+	assert.NoError(t, err)
+	assert.Equal(t, "sl.abc123", value)
+
+	assert.NoError(t, reopened.Delete("cloud.dropbox.token"))
+	_, err = reopened.Get("cloud.dropbox.token")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.ErrorIs(t, reopened.Delete("cloud.dropbox.token"), ErrNotFound)
+}
+
+func TestFileStoreWrongPassphrase(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "secrets.enc")
+	store, err := NewFileStore(path, "correct horse")
+	assert.NoError(t, err)
+	assert.NoError(t, store.Set("key", "value"))
+
+	_, err = NewFileStore(path, "battery staple")
+	assert.Error(t, err)
+
+	_, err = NewFileStore(path, "")
+	assert.Error(t, err)
+}
+
+func TestOpenFileBackend(t *testing.T) {
+	configDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configDir)
+	t.Setenv("HOME", configDir)
+	t.Setenv(BackendEnv, "file")
+	t.Setenv(PassphraseEnv, "")
+
+	// Without a passphrase source the file store cannot be opened
+	_, err := Open(nil)
+	assert.Error(t, err)
+
+	store, err := Open(func() (string, error) { return "prompted", nil })
+	assert.NoError(t, err)
+	assert.Contains(t, store.Name(), "encrypted file")
+
+	t.Setenv(BackendEnv, "bogus")
+	_, err = Open(nil)
+	assert.Error(t, err)
+}
+
+func TestQuoteSecurityArg(t *testing.T) {
+	assert.Equal(t, `"plain"`, quoteSecurityArg("plain"))
+	assert.Equal(t, `"a \"b\" c\\d"`, quoteSecurityArg(`a "b" c\d`))
+}
+
+func TestKeyringGetErrors(t *testing.T) {
+	if runtime.GOOS == "windows" || runtime.GOOS == "darwin" {
+		t.Skip("fake tool mimics secret-tool")
+	}
+	dir := t.TempDir()
+	fake := func(script string) *commandKeyring {
+		path := filepath.Join(dir, "secret-tool")
+		assert.NoError(t, os.WriteFile(path, []byte("#!/bin/sh\n"+script+"\n"), 0755))
+		return &commandKeyring{tool: path}
+	}
+
+	_, err := fake("exit 1").Get("cloud.dropbox.token")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	// A locked or unreachable keyring is not a missing secret
+	_, err = fake("echo 'Cannot autolaunch D-Bus' >&2; exit 1").Get("cloud.dropbox.token")
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, ErrNotFound)
+	assert.Contains(t, err.Error(), "D-Bus")
+
+	value, err := fake("echo token").Get("cloud.dropbox.token")
+	assert.NoError(t, err)
+	assert.Equal(t, "token", value)
+}
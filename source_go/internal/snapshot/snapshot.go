@@ -0,0 +1,184 @@
+package snapshot
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ebook-renamer/go/internal/state"
+)
+
+// Version is the archive format version
+const Version = 1
+
+const manifestName = "manifest.json"
+
+// Largest state file accepted on import; journals of very large libraries stay well below this
+const maxFileSize = 512 << 20
+
+// Manifest describes an exported archive
+type Manifest struct {
+	Version     int       `json:"version"`
+	CreatedAt   time.Time `json:"created_at"`
+	LibraryRoot string    `json:"library_root"`
+	Files       []string  `json:"files"`
+}
+
+// ErrExists is returned by Import when it would overwrite existing state
+var ErrExists = errors.New("state already exists")
+
+// Export writes the library state files that exist to w as a gzipped tar archive.
+// Paths inside the state are library-relative, so the archive works for a library at a different location.
+func Export(layout *state.Layout, w io.Writer) (*Manifest, error) {
+	manifest := &Manifest{Version: Version, CreatedAt: time.Now().UTC(), LibraryRoot: layout.LibraryRoot}
+	var present []string
+	for _, path := range layout.Files() {
+		if _, err := os.Stat(path); err == nil {
+			present = append(present, path)
+			manifest.Files = append(manifest.Files, filepath.Base(path))
+		} else if !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+	if len(present) == 0 {
+		return nil, fmt.Errorf("no state found for %s", layout.LibraryRoot)
+	}
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := writeEntry(tw, manifestName, manifestData, manifest.CreatedAt); err != nil {
+		return nil, err
+	}
+	for _, path := range present {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if err := writeEntry(tw, filepath.Base(path), data, manifest.CreatedAt); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+func writeEntry(tw *tar.Writer, name string, data []byte, modTime time.Time) error {
+	header := &tar.Header{Name: name, Mode: 0644, Size: int64(len(data)), ModTime: modTime, Typeflag: tar.TypeReg}
+	if err := tw.WriteHeader(header); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	if _, err := tw.Write(data); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return nil
+}
+
+// Import restores state files from an archive written by Export into the layout.
+// Nothing is written unless the whole archive is valid, and existing state is only
+// replaced when force is set.
+func Import(layout *state.Layout, r io.Reader, force bool) (*Manifest, error) {
+	// Only names the layout knows are accepted, which also rules out path traversal
+	targets := make(map[string]string)
+	for _, path := range layout.Files() {
+		targets[filepath.Base(path)] = path
+	}
+
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("not a state archive: %w", err)
+	}
+	defer gz.Close()
+
+	var manifest *Manifest
+	contents := make(map[string][]byte)
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("corrupt state archive: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			return nil, fmt.Errorf("unexpected entry in state archive: %s", header.Name)
+		}
+		if _, known := targets[header.Name]; !known && header.Name != manifestName {
+			return nil, fmt.Errorf("unexpected entry in state archive: %s", header.Name)
+		}
+		if header.Size > maxFileSize {
+			return nil, fmt.Errorf("%s is too large (%d bytes)", header.Name, header.Size)
+		}
+
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", header.Name, err)
+		}
+		if header.Name == manifestName {
+			manifest = &Manifest{}
+			if err := json.Unmarshal(data, manifest); err != nil {
+				return nil, fmt.Errorf("invalid manifest: %w", err)
+			}
+			continue
+		}
+		contents[header.Name] = data
+	}
+
+	if manifest == nil {
+		return nil, fmt.Errorf("state archive has no manifest")
+	}
+	if manifest.Version > Version {
+		return nil, fmt.Errorf("state archive version %d is newer than supported version %d", manifest.Version, Version)
+	}
+
+	if !force {
+		for name := range contents {
+			if _, err := os.Stat(targets[name]); err == nil {
+				return nil, fmt.Errorf("%w: %s", ErrExists, targets[name])
+			}
+		}
+	}
+
+	if err := layout.Ensure(); err != nil {
+		return nil, err
+	}
+	for name, data := range contents {
+		if err := writeAtomic(targets[name], data); err != nil {
+			return nil, fmt.Errorf("failed to restore %s: %w", name, err)
+		}
+	}
+	return manifest, nil
+}
+
+func writeAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,81 @@
+package snapshot
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ebook-renamer/go/internal/state"
+	"github.com/stretchr/testify/assert"
+)
+
+func portableLayout(t *testing.T) *state.Layout {
+	layout, err := state.Resolve(t.TempDir(), true)
+	assert.NoError(t, err)
+	assert.NoError(t, layout.Ensure())
+	return layout
+}
+
+func TestExportImportRoundTrip(t *testing.T) {
+	src := portableLayout(t)
+	assert.NoError(t, os.WriteFile(src.IndexPath(), []byte(`{"version":1}`), 0644))
+	assert.NoError(t, os.WriteFile(src.JournalPath(), []byte("{\"op\":\"rename\"}\n"), 0644))
+	assert.NoError(t, os.WriteFile(src.ConfigPath(), []byte(`{"no_delete":true}`), 0644))
+	// Other files in the state directory are not part of the snapshot
+	assert.NoError(t, os.WriteFile(filepath.Join(src.Dir, "scratch.tmp"), []byte("x"), 0644))
+
+	var buf bytes.Buffer
+	manifest, err := Export(src, &buf)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"index.json", "journal.jsonl", "config.json"}, manifest.Files)
+	archive := buf.Bytes()
+
+	dst := portableLayout(t)
+	imported, err := Import(dst, bytes.NewReader(archive), false)
+	assert.NoError(t, err)
+	assert.Equal(t, src.LibraryRoot, imported.LibraryRoot)
+
+	data, err := os.ReadFile(dst.JournalPath())
+	assert.NoError(t, err)
+	assert.Equal(t, "{\"op\":\"rename\"}\n", string(data))
+	_, err = os.Stat(filepath.Join(dst.Dir, "scratch.tmp"))
+	assert.True(t, os.IsNotExist(err))
+
+	// Existing state is only replaced with force
+	assert.NoError(t, os.WriteFile(dst.JournalPath(), []byte("newer\n"), 0644))
+	_, err = Import(dst, bytes.NewReader(archive), false)
+	assert.ErrorIs(t, err, ErrExists)
+	data, _ = os.ReadFile(dst.JournalPath())
+	assert.Equal(t, "newer\n", string(data))
+
+	_, err = Import(dst, bytes.NewReader(archive), true)
+	assert.NoError(t, err)
+	data, _ = os.ReadFile(dst.JournalPath())
+	assert.Equal(t, "{\"op\":\"rename\"}\n", string(data))
+}
+
+func TestExportWithoutState(t *testing.T) {
+	_, err := Export(portableLayout(t), &bytes.Buffer{})
+	assert.Error(t, err)
+}
+
+func TestImportRejectsUnknownEntries(t *testing.T) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	data := []byte("evil")
+	assert.NoError(t, tw.WriteHeader(&tar.Header{Name: "../../escape.json", Mode: 0644, Size: int64(len(data)), Typeflag: tar.TypeReg}))
+	_, err := tw.Write(data)
+	assert.NoError(t, err)
+	assert.NoError(t, tw.Close())
+	assert.NoError(t, gz.Close())
+
+	dst := portableLayout(t)
+	_, err = Import(dst, &buf, true)
+	assert.ErrorContains(t, err, "unexpected entry")
+	_, err = os.Stat(filepath.Join(dst.Dir, "..", "..", "escape.json"))
+	assert.True(t, os.IsNotExist(err))
+}
@@ -0,0 +1,120 @@
+package state
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DirName is the directory inside a library that holds its state in portable mode.
+// The scanner skips hidden directories, so the state never shows up as library content.
+const DirName = ".ebook-renamer"
+
+// Layout describes where the renamer state for one library lives
+type Layout struct {
+	LibraryRoot string
+	Dir         string
+	Portable    bool
+}
+
+// Resolve determines the state layout for a library.
+// Portable mode is used when requested or when the library already contains a state directory;
+// otherwise the state lives in the user's config directory, keyed by the library path.
+func Resolve(libraryRoot string, portable bool) (*Layout, error) {
+	root, err := filepath.Abs(libraryRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	portableDir := filepath.Join(root, DirName)
+	if !portable {
+		if info, err := os.Stat(portableDir); err == nil && info.IsDir() {
+			portable = true
+		}
+	}
+	if portable {
+		return &Layout{LibraryRoot: root, Dir: portableDir, Portable: true}, nil
+	}
+
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return nil, fmt.Errorf("no user config directory: %w", err)
+	}
+	key := fmt.Sprintf("%x", sha256.Sum256([]byte(root)))[:16]
+	return &Layout{
+		LibraryRoot: root,
+		Dir:         filepath.Join(configDir, "ebook-renamer", "libraries", key),
+	}, nil
+}
+
+// Ensure creates the state directory if needed
+func (l *Layout) Ensure() error {
+	return os.MkdirAll(l.Dir, 0755)
+}
+
+// IndexPath returns the path of the library index
+func (l *Layout) IndexPath() string {
+	return filepath.Join(l.Dir, "index.json")
+}
+
+// JournalPath returns the path of the operation journal
+func (l *Layout) JournalPath() string {
+	return filepath.Join(l.Dir, "journal.jsonl")
+}
+
+// ConfigPath returns the path of the per-library config file
+func (l *Layout) ConfigPath() string {
+	return filepath.Join(l.Dir, "config.json")
+}
+
+// Files returns the state files worth keeping when a library moves, in a stable order
+func (l *Layout) Files() []string {
+	return []string{l.IndexPath(), l.JournalPath(), l.ConfigPath()}
+}
+
+// Rel converts a path to a library-relative path with forward slashes.
+// Paths outside the library are returned unchanged.
+func (l *Layout) Rel(path string) string {
+	rel, err := filepath.Rel(l.LibraryRoot, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return path
+	}
+	return filepath.ToSlash(rel)
+}
+
+// Abs resolves a library-relative path against the current library location
+func (l *Layout) Abs(rel string) string {
+	if filepath.IsAbs(rel) {
+		return rel
+	}
+	return filepath.Join(l.LibraryRoot, filepath.FromSlash(rel))
+}
+
+// Config holds per-library defaults; command-line flags take precedence
+type Config struct {
+	TodoFile        string   `json:"todo_file,omitempty"`
+	NoDelete        *bool    `json:"no_delete,omitempty"`
+	SkipCloudHash   *bool    `json:"skip_cloud_hash,omitempty"`
+	AutoThreshold   *float64 `json:"auto_threshold,omitempty"`
+	ReviewThreshold *float64 `json:"review_threshold,omitempty"`
+}
+
+// LoadConfig reads the per-library config, returning an empty config if there is none
+func (l *Layout) LoadConfig() (*Config, error) {
+	data, err := os.ReadFile(l.ConfigPath())
+	if os.IsNotExist(err) {
+		return &Config{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var config Config
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("invalid config %s: %w", l.ConfigPath(), err)
+	}
+	return &config, nil
+}
@@ -0,0 +1,73 @@
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolvePortable(t *testing.T) {
+	libDir := t.TempDir()
+
+	layout, err := Resolve(libDir, true)
+	assert.NoError(t, err)
+	assert.True(t, layout.Portable)
+	assert.Equal(t, filepath.Join(libDir, DirName), layout.Dir)
+	assert.Equal(t, filepath.Join(libDir, DirName, "journal.jsonl"), layout.JournalPath())
+}
+
+func TestResolveDetectsPortableDir(t *testing.T) {
+	libDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+
+	layout, err := Resolve(libDir, false)
+	assert.NoError(t, err)
+	assert.False(t, layout.Portable)
+	assert.NotContains(t, layout.Dir, libDir)
+
+	assert.NoError(t, os.Mkdir(filepath.Join(libDir, DirName), 0755))
+	layout, err = Resolve(libDir, false)
+	assert.NoError(t, err)
+	assert.True(t, layout.Portable)
+}
+
+func TestRelAndAbs(t *testing.T) {
+	libDir := t.TempDir()
+	layout, _ := Resolve(libDir, true)
+
+	rel := layout.Rel(filepath.Join(libDir, "math", "book.pdf"))
+	assert.Equal(t, "math/book.pdf", rel)
+	assert.Equal(t, filepath.Join(libDir, "math", "book.pdf"), layout.Abs(rel))
+
+	// Paths outside the library stay absolute
+	outside := filepath.Join(filepath.Dir(libDir), "elsewhere.pdf")
+	assert.Equal(t, outside, layout.Rel(outside))
+
+	// A moved library resolves the same relative paths against its new root
+	moved := &Layout{LibraryRoot: "/media/usb/library"}
+	assert.Equal(t, filepath.Join("/media/usb/library", "math", "book.pdf"), moved.Abs(rel))
+}
+
+func TestLoadConfig(t *testing.T) {
+	libDir := t.TempDir()
+	layout, _ := Resolve(libDir, true)
+
+	config, err := layout.LoadConfig()
+	assert.NoError(t, err)
+	assert.Nil(t, config.NoDelete)
+
+	assert.NoError(t, layout.Ensure())
+	assert.NoError(t, os.WriteFile(layout.ConfigPath(), []byte(`{"todo_file": "notes/todo.md", "no_delete": true}`), 0644))
+
+	config, err = layout.LoadConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, "notes/todo.md", config.TodoFile)
+	assert.True(t, *config.NoDelete)
+
+	assert.NoError(t, os.WriteFile(layout.ConfigPath(), []byte(`{not json`), 0644))
+	_, err = layout.LoadConfig()
+	assert.Error(t, err)
+}
@@ -0,0 +1,68 @@
+package summary
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Number of todo items included in the copied summary
+const maxTodoLines = 10
+
+// Summary holds the counts of one run
+type Summary struct {
+	Time       time.Time
+	Library    string
+	DryRun     bool
+	Rejected   bool // the sample review was rejected, so nothing was applied
+	Renamed    int
+	Duplicates int
+	Cleaned    int
+	Conflicts  int
+	Todo       []string
+}
+
+// OneLine renders the run as a single line suitable for pasting into notes
+func (s *Summary) OneLine() string {
+	var parts []string
+	if s.Rejected {
+		parts = append(parts, "no changes applied")
+	}
+	add := func(n int, label string) {
+		if n > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", n, label))
+		}
+	}
+	add(s.Renamed, "renamed")
+	add(s.Duplicates, "duplicates removed")
+	add(s.Cleaned, "cleaned up")
+	add(s.Conflicts, "skipped (conflicts)")
+	add(len(s.Todo), "todo")
+	if len(parts) == 0 {
+		parts = append(parts, "nothing to do")
+	}
+
+	mode := ""
+	if s.DryRun {
+		mode = " (dry run)"
+	} else if s.Rejected {
+		mode = " (sample rejected)"
+	}
+	return fmt.Sprintf("[%s] ebook-renamer %s%s: %s", s.Time.Format("2006-01-02"), filepath.Base(s.Library), mode, strings.Join(parts, ", "))
+}
+
+// Text renders the one-line summary followed by the first todo items as a checklist
+func (s *Summary) Text() string {
+	var b strings.Builder
+	b.WriteString(s.OneLine())
+	b.WriteString("\n")
+	for i, item := range s.Todo {
+		if i == maxTodoLines {
+			fmt.Fprintf(&b, "- … %d more in todo.md\n", len(s.Todo)-maxTodoLines)
+			break
+		}
+		fmt.Fprintf(&b, "- [ ] %s\n", item)
+	}
+	return b.String()
+}
@@ -0,0 +1,39 @@
+package summary
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOneLine(t *testing.T) {
+	s := &Summary{
+		Time:       time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+		Library:    "/home/me/Books",
+		Renamed:    12,
+		Duplicates: 3,
+		Todo:       []string{"a", "b"},
+	}
+	assert.Equal(t, "[2026-03-01] ebook-renamer Books: 12 renamed, 3 duplicates removed, 2 todo", s.OneLine())
+
+	s = &Summary{Time: s.Time, Library: "/home/me/Books", DryRun: true}
+	assert.Equal(t, "[2026-03-01] ebook-renamer Books (dry run): nothing to do", s.OneLine())
+
+	s = &Summary{Time: s.Time, Library: "/home/me/Books", Rejected: true, Todo: []string{"a"}}
+	assert.Equal(t, "[2026-03-01] ebook-renamer Books (sample rejected): no changes applied, 1 todo", s.OneLine())
+}
+
+func TestTextLimitsTodoItems(t *testing.T) {
+	s := &Summary{Time: time.Now(), Library: "/lib"}
+	for i := 0; i < maxTodoLines+3; i++ {
+		s.Todo = append(s.Todo, fmt.Sprintf("item %d", i))
+	}
+
+	lines := strings.Split(strings.TrimSpace(s.Text()), "\n")
+	assert.Len(t, lines, maxTodoLines+2)
+	assert.Equal(t, "- [ ] item 0", lines[1])
+	assert.Equal(t, "- … 3 more in todo.md", lines[len(lines)-1])
+}
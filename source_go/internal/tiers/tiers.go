@@ -0,0 +1,70 @@
+package tiers
+
+import (
+	"fmt"
+)
+
+// Tier is the execution bucket an operation falls into based on its confidence
+type Tier string
+
+const (
+	TierAuto   Tier = "auto"
+	TierReview Tier = "review"
+	TierSkip   Tier = "skip"
+)
+
+// Default thresholds used when none are configured
+const (
+	DefaultAutoThreshold   = 0.8
+	DefaultReviewThreshold = 0.5
+)
+
+// Thresholds holds the minimum confidence for each tier.
+// Operations at or above Auto are applied, those at or above Review need review,
+// and everything else is skipped.
+type Thresholds struct {
+	Auto   float64
+	Review float64
+}
+
+// Validate checks that the thresholds are within [0, 1] and correctly ordered
+func (t Thresholds) Validate() error {
+	if t.Auto < 0 || t.Auto > 1 || t.Review < 0 || t.Review > 1 {
+		return fmt.Errorf("thresholds must be between 0 and 1 (auto: %.2f, review: %.2f)", t.Auto, t.Review)
+	}
+	if t.Review > t.Auto {
+		return fmt.Errorf("review threshold %.2f is above auto threshold %.2f", t.Review, t.Auto)
+	}
+	return nil
+}
+
+// Classify returns the tier for the given confidence
+func (t Thresholds) Classify(confidence float64) Tier {
+	switch {
+	case confidence >= t.Auto:
+		return TierAuto
+	case confidence >= t.Review:
+		return TierReview
+	default:
+		return TierSkip
+	}
+}
+
+// Counts tallies how many operations landed in each tier
+type Counts struct {
+	Auto   int
+	Review int
+	Skip   int
+}
+
+// Add records one operation in the given tier
+func (c *Counts) Add(tier Tier) {
+	switch tier {
+	case TierAuto:
+		c.Auto++
+	case TierReview:
+		c.Review++
+	default:
+		c.Skip++
+	}
+}
@@ -0,0 +1,34 @@
+package tiers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassify(t *testing.T) {
+	th := Thresholds{Auto: DefaultAutoThreshold, Review: DefaultReviewThreshold}
+
+	assert.Equal(t, TierAuto, th.Classify(1.0))
+	assert.Equal(t, TierAuto, th.Classify(0.8))
+	assert.Equal(t, TierReview, th.Classify(0.79))
+	assert.Equal(t, TierReview, th.Classify(0.5))
+	assert.Equal(t, TierSkip, th.Classify(0.49))
+}
+
+func TestValidate(t *testing.T) {
+	assert.NoError(t, Thresholds{Auto: 0.9, Review: 0.6}.Validate())
+	assert.NoError(t, Thresholds{Auto: 0.7, Review: 0.7}.Validate())
+	assert.Error(t, Thresholds{Auto: 0.5, Review: 0.6}.Validate())
+	assert.Error(t, Thresholds{Auto: 1.5, Review: 0.6}.Validate())
+	assert.Error(t, Thresholds{Auto: 0.9, Review: -0.1}.Validate())
+}
+
+func TestCounts(t *testing.T) {
+	var c Counts
+	c.Add(TierAuto)
+	c.Add(TierAuto)
+	c.Add(TierReview)
+	c.Add(TierSkip)
+	assert.Equal(t, Counts{Auto: 2, Review: 1, Skip: 1}, c)
+}
@@ -4,9 +4,11 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/ebook-renamer/go/internal/i18n"
 	"github.com/ebook-renamer/go/internal/types"
 )
 
@@ -19,10 +21,12 @@ type TodoList struct {
 	smallFiles      []string
 	corruptedFiles  []string
 	otherIssues     []string
+	reviewItems     []string
+	lang            i18n.Lang
 }
 
-// New creates a new TodoList instance
-func New(todoFilePath, targetDir string) (*TodoList, error) {
+// New creates a new TodoList instance whose items are written in lang
+func New(todoFilePath, targetDir string, lang i18n.Lang) (*TodoList, error) {
 	// Determine todo file path
 	if todoFilePath == "" {
 		todoFilePath = filepath.Join(targetDir, "todo.md")
@@ -45,25 +49,30 @@ func New(todoFilePath, targetDir string) (*TodoList, error) {
 		smallFiles:      []string{},
 		corruptedFiles:  []string{},
 		otherIssues:     []string{},
+		reviewItems:     []string{},
+		lang:            lang,
 	}, nil
 }
 
 // AddFileIssue adds a file issue to the todo list
 func (tl *TodoList) AddFileIssue(fileInfo *types.FileInfo, issue types.FileIssue) error {
-	var item string
-	
+	var id i18n.MessageID
+	params := i18n.Params{"file": fileInfo.OriginalName}
+
 	switch issue {
 	case types.FileIssueFailedDownload:
-		item = fmt.Sprintf("重新下载: %s (未完成下载)", fileInfo.OriginalName)
+		id = i18n.MsgRedownloadIncomplete
 	case types.FileIssueTooSmall:
-		item = fmt.Sprintf("检查并重新下载: %s (文件过小，仅 %d 字节)", fileInfo.OriginalName, fileInfo.Size)
+		id = i18n.MsgRedownloadSmallFile
+		params["size"] = strconv.FormatUint(fileInfo.Size, 10)
 	case types.FileIssueCorruptedPdf:
-		item = fmt.Sprintf("重新下载: %s (PDF文件损坏或格式无效)", fileInfo.OriginalName)
+		id = i18n.MsgRedownloadCorrupted
 	case types.FileIssueReadError:
-		item = fmt.Sprintf("检查文件权限: %s (无法读取文件)", fileInfo.OriginalName)
+		id = i18n.MsgCheckReadError
 	default:
-		item = fmt.Sprintf("检查文件: %s (未知问题)", fileInfo.OriginalName)
+		id = i18n.MsgCheckUnknownIssue
 	}
+	item := i18n.Format(tl.lang, id, params)
 
 	// Check if item already exists
 	for _, existing := range tl.items {
@@ -88,6 +97,66 @@ func (tl *TodoList) AddFileIssue(fileInfo *types.FileInfo, issue types.FileIssue
 	return nil
 }
 
+// AddRenameReview adds a rename whose confidence was too low to apply automatically
+func (tl *TodoList) AddRenameReview(fileInfo *types.FileInfo) error {
+	if fileInfo.NewName == nil {
+		return nil
+	}
+	tl.addReviewItem(i18n.Format(tl.lang, i18n.MsgReviewRename, RenameReviewParams(fileInfo)))
+	return nil
+}
+
+// AddDuplicateReview adds a duplicate group whose confidence was too low to delete automatically
+func (tl *TodoList) AddDuplicateReview(group []string, confidence float64) error {
+	if len(group) < 2 {
+		return nil
+	}
+	tl.addReviewItem(i18n.Format(tl.lang, i18n.MsgReviewDuplicates, DuplicateReviewParams(group, confidence)))
+	return nil
+}
+
+// AddCleanupReview adds a problem file whose confidence was too low to delete automatically
+func (tl *TodoList) AddCleanupReview(path string, confidence float64) error {
+	tl.addReviewItem(i18n.Format(tl.lang, i18n.MsgReviewCleanup, CleanupReviewParams(path, confidence)))
+	return nil
+}
+
+// AddNearDuplicate adds a copy that only differs by a few extra pages; these are never deleted automatically
+func (tl *TodoList) AddNearDuplicate(nd types.NearDuplicate) error {
+	tl.addReviewItem(i18n.Format(tl.lang, i18n.MsgReviewNearDuplicate, NearDuplicateParams(nd)))
+	return nil
+}
+
+// RenameReviewParams returns the message parameters of a rename review item
+func RenameReviewParams(fileInfo *types.FileInfo) i18n.Params {
+	return i18n.Params{"file": fileInfo.OriginalName, "new_name": *fileInfo.NewName, "confidence": i18n.Confidence(fileInfo.Confidence)}
+}
+
+// DuplicateReviewParams returns the message parameters of a duplicate review item
+func DuplicateReviewParams(group []string, confidence float64) i18n.Params {
+	return i18n.Params{"keep": filepath.Base(group[0]), "copies": strconv.Itoa(len(group) - 1), "confidence": i18n.Confidence(confidence)}
+}
+
+// CleanupReviewParams returns the message parameters of a cleanup review item
+func CleanupReviewParams(path string, confidence float64) i18n.Params {
+	return i18n.Params{"file": filepath.Base(path), "confidence": i18n.Confidence(confidence)}
+}
+
+// NearDuplicateParams returns the message parameters of a near-duplicate review item
+func NearDuplicateParams(nd types.NearDuplicate) i18n.Params {
+	return i18n.Params{"file": filepath.Base(nd.Duplicate), "keep": filepath.Base(nd.Keep), "extra_pages": strconv.Itoa(nd.ExtraPages), "confidence": i18n.Confidence(nd.Confidence)}
+}
+
+func (tl *TodoList) addReviewItem(item string) {
+	for _, existing := range tl.items {
+		if existing == item {
+			return
+		}
+	}
+	tl.reviewItems = append(tl.reviewItems, item)
+	tl.items = append(tl.items, item)
+}
+
 // AddFailedDownload adds a failed download file to the todo list
 func (tl *TodoList) AddFailedDownload(fileInfo *types.FileInfo) error {
 	if fileInfo.IsFailedDownload {
@@ -138,6 +207,7 @@ func (tl *TodoList) RemoveFileFromTodo(filename string) {
 	tl.smallFiles = filterList(tl.smallFiles, filenameLower)
 	tl.corruptedFiles = filterList(tl.corruptedFiles, filenameLower)
 	tl.otherIssues = filterList(tl.otherIssues, filenameLower)
+	tl.reviewItems = filterList(tl.reviewItems, filenameLower)
 }
 
 // Write writes the todo list to the markdown file
@@ -266,6 +336,15 @@ func (tl *TodoList) generateTodoMD() string {
 		md.WriteString("\n")
 	}
 
+	if len(tl.reviewItems) > 0 {
+		md.WriteString("## 🔍 待审核的操作\n\n")
+		md.WriteString("> 这些操作的置信度不足以自动执行，请确认后手动处理。\n\n")
+		for _, item := range tl.reviewItems {
+			md.WriteString(fmt.Sprintf("- [ ] %s\n", item))
+		}
+		md.WriteString("\n")
+	}
+
 	// Add other items that don't fit in categories
 	var otherItems []string
 	for _, item := range tl.items {
@@ -294,6 +373,12 @@ func (tl *TodoList) generateTodoMD() string {
 				break
 			}
 		}
+		for _, catItem := range tl.reviewItems {
+			if item == catItem {
+				isInCategory = true
+				break
+			}
+		}
 
 		if !isInCategory {
 			otherItems = append(otherItems, item)
@@ -308,7 +393,7 @@ func (tl *TodoList) generateTodoMD() string {
 		md.WriteString("\n")
 	}
 
-	if len(tl.failedDownloads) == 0 && len(tl.smallFiles) == 0 && len(tl.corruptedFiles) == 0 && len(tl.otherIssues) == 0 && len(otherItems) == 0 {
+	if len(tl.failedDownloads) == 0 && len(tl.smallFiles) == 0 && len(tl.corruptedFiles) == 0 && len(tl.otherIssues) == 0 && len(tl.reviewItems) == 0 && len(otherItems) == 0 {
 		md.WriteString("## ✅ 状态\n\n")
 		md.WriteString("所有文件已检查完毕，未发现需要处理的问题。\n\n")
 	}
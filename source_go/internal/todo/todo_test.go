@@ -5,6 +5,7 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/ebook-renamer/go/internal/i18n"
 	"github.com/ebook-renamer/go/internal/types"
 	"github.com/stretchr/testify/assert"
 )
@@ -15,7 +16,7 @@ func TestTodoListNewAndWrite(t *testing.T) {
 	todoFile := filepath.Join(tmpDir, "todo.md")
 
 	// Create new TodoList
-	tl, err := New(todoFile, tmpDir)
+	tl, err := New(todoFile, tmpDir, i18n.Default)
 	assert.NoError(t, err)
 	assert.NotNil(t, tl)
 
@@ -62,7 +63,7 @@ func TestAnalyzeFileIntegrityCorruptedPDF(t *testing.T) {
 	err := os.WriteFile(filePath, []byte("NOT PDF CONTENT"), 0644)
 	assert.NoError(t, err)
 
-	tl, _ := New("", tmpDir)
+	tl, _ := New("", tmpDir, i18n.Default)
 	fileInfo := &types.FileInfo{
 		OriginalName: "bad.pdf",
 		OriginalPath: filePath,
@@ -86,7 +87,7 @@ func TestAnalyzeFileIntegrityValidPDF(t *testing.T) {
 	err := os.WriteFile(filePath, []byte("%PDF-1.4\n..."), 0644)
 	assert.NoError(t, err)
 
-	tl, _ := New("", tmpDir)
+	tl, _ := New("", tmpDir, i18n.Default)
 	fileInfo := &types.FileInfo{
 		OriginalName: "good.pdf",
 		OriginalPath: filePath,
@@ -102,7 +103,7 @@ func TestAnalyzeFileIntegrityValidPDF(t *testing.T) {
 }
 
 func TestRemoveFileFromTodo(t *testing.T) {
-	tl, _ := New("", ".")
+	tl, _ := New("", ".", i18n.Default)
 
 	fileInfo := &types.FileInfo{OriginalName: "remove_me.pdf"}
 	tl.AddFileIssue(fileInfo, types.FileIssueCorruptedPdf)
@@ -115,3 +116,38 @@ func TestRemoveFileFromTodo(t *testing.T) {
 	assert.Empty(t, tl.corruptedFiles)
 	assert.Empty(t, tl.items)
 }
+
+func TestAddReviewItems(t *testing.T) {
+	tmpDir := t.TempDir()
+	tl, _ := New("", tmpDir, i18n.Default)
+
+	newName := "John Smith - Book.pdf"
+	fileInfo := &types.FileInfo{OriginalName: "john smith book.pdf", NewName: &newName, Confidence: 0.6}
+	assert.NoError(t, tl.AddRenameReview(fileInfo))
+	assert.NoError(t, tl.AddRenameReview(fileInfo)) // Deduplicated
+	assert.NoError(t, tl.AddDuplicateReview([]string{"/lib/a.pdf", "/lib/b.pdf"}, 0.7))
+	assert.NoError(t, tl.AddCleanupReview("/lib/odd.pdf", 0.5))
+
+	assert.Len(t, tl.reviewItems, 3)
+	assert.Contains(t, tl.reviewItems[0], "置信度 0.60")
+	assert.Contains(t, tl.reviewItems[1], "a.pdf")
+	assert.Equal(t, "审核删除: odd.pdf (置信度 0.50)", tl.reviewItems[2])
+
+	assert.NoError(t, tl.Write())
+	content, err := os.ReadFile(filepath.Join(tmpDir, "todo.md"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(content), "待审核的操作")
+}
+
+func TestItemsFollowLanguage(t *testing.T) {
+	tl, _ := New("", t.TempDir(), i18n.English)
+
+	fileInfo := &types.FileInfo{OriginalName: "broken.pdf"}
+	assert.NoError(t, tl.AddFileIssue(fileInfo, types.FileIssueCorruptedPdf))
+	assert.NoError(t, tl.AddCleanupReview("/lib/odd.pdf", 0.5))
+
+	// Same message as the JSON todo item for this file
+	assert.Equal(t, i18n.Format(i18n.English, i18n.MsgRedownloadCorrupted, i18n.Params{"file": "broken.pdf"}), tl.corruptedFiles[0])
+	assert.Equal(t, "Re-download: broken.pdf (corrupted or invalid PDF)", tl.corruptedFiles[0])
+	assert.Equal(t, "Review deletion: odd.pdf (confidence 0.50)", tl.reviewItems[0])
+}
@@ -11,8 +11,13 @@ import (
 	"github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/ebook-renamer/go/internal/duplicates"
+	"github.com/ebook-renamer/go/internal/i18n"
+	"github.com/ebook-renamer/go/internal/index"
+	"github.com/ebook-renamer/go/internal/journal"
+	"github.com/ebook-renamer/go/internal/lock"
 	"github.com/ebook-renamer/go/internal/normalizer"
 	"github.com/ebook-renamer/go/internal/scanner"
+	"github.com/ebook-renamer/go/internal/state"
 	"github.com/ebook-renamer/go/internal/todo"
 	"github.com/ebook-renamer/go/internal/types"
 )
@@ -46,6 +51,7 @@ type Model struct {
 	cleanFiles      []*types.FileInfo
 	todoList        *todo.TodoList
 	filesToDelete   []string
+	conflicts       []types.Conflict
 }
 
 func NewModel(config *types.Config) Model {
@@ -123,6 +129,10 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			cmds = append(cmds, m.executeCmd)
 		}
 	case executeMsg:
+		m.conflicts = msg.conflicts
+		for _, c := range m.conflicts {
+			m.logs = append(m.logs, fmt.Sprintf("Conflict, skipped %s: %s", c.Path, c.Reason))
+		}
 		m.logs = append(m.logs, "Execution complete")
 		m.state = StepDone
 		cmds = append(cmds, tea.Quit)
@@ -156,6 +166,13 @@ func (m Model) View() string {
 
 	s += "\n"
 	s += m.viewport.View()
+	if len(m.conflicts) > 0 {
+		// Listed outside the viewport so none scroll out of sight
+		s += fmt.Sprintf("\n⚠️  Skipped %d conflicts (files changed since the scan):\n", len(m.conflicts))
+		for _, c := range m.conflicts {
+			s += fmt.Sprintf("  %s: %s\n", c.Path, c.Reason)
+		}
+	}
 	s += "\nPress q to quit.\n"
 
 	return s
@@ -202,7 +219,7 @@ func (m Model) checkIntegrityCmd() tea.Msg {
 		todoFilePath = *m.config.TodoFile
 	}
 
-	todoList, err := todo.New(todoFilePath, m.config.Path)
+	todoList, err := todo.New(todoFilePath, m.config.Path, i18n.Lang(m.config.Lang))
 	if err != nil {
 		return errMsg(err)
 	}
@@ -281,6 +298,9 @@ func (m Model) detectDuplicatesCmd() tea.Msg {
 	if err != nil {
 		return errMsg(err)
 	}
+	if m.config.MergeMetadata && !m.config.NoDelete {
+		groups, clean, _ = duplicates.MergeGroupMetadata(groups, m.normalized, clean, !m.config.SkipCloudHash)
+	}
 	return duplicatesMsg{groups: groups, clean: clean}
 }
 
@@ -293,26 +313,62 @@ func (m Model) writeTodoCmd() tea.Msg {
 	return writeTodoMsg{}
 }
 
-type executeMsg struct{}
+type executeMsg struct {
+	conflicts []types.Conflict
+}
 
 func (m Model) executeCmd() tea.Msg {
-	// Execute renames
-	for _, fileInfo := range m.cleanFiles {
-		if fileInfo.NewName != nil {
-			if err := os.Rename(fileInfo.OriginalPath, fileInfo.NewPath); err != nil {
-				return errMsg(err)
-			}
-		}
+	libLock, err := lock.Acquire(m.config.Path, m.config.BreakLock)
+	if err != nil {
+		return errMsg(err)
+	}
+	defer libLock.ReleaseOrLog()
+
+	layout, err := state.Resolve(m.config.Path, m.config.Portable)
+	if err != nil {
+		return errMsg(err)
+	}
+	if err := layout.Ensure(); err != nil {
+		return errMsg(err)
+	}
+	user, host := lock.Identity()
+	j, err := journal.Open(layout.JournalPath(), layout.Rel, user, host)
+	if err != nil {
+		return errMsg(err)
+	}
+	defer j.Close()
+
+	byPath := make(map[string]*types.FileInfo, len(m.normalized))
+	for _, fileInfo := range m.normalized {
+		byPath[fileInfo.OriginalPath] = fileInfo
+	}
+
+	// Skip files another user changed since the scan
+	var conflicts []types.Conflict
+	conflict := func(path string, err error) {
+		conflicts = append(conflicts, types.Conflict{Path: path, Reason: err.Error()})
 	}
 
 	// Delete duplicates
 	if !m.config.NoDelete {
 		for _, group := range m.duplicateGroups {
 			if len(group) > 1 {
+				if err := lock.CheckKept(byPath[group[0]]); err != nil {
+					conflict(group[0], err)
+					continue
+				}
 				for i, path := range group {
 					if i > 0 {
+						if fileInfo, ok := byPath[path]; ok {
+							if err := lock.CheckUnchanged(fileInfo); err != nil {
+								conflict(path, err)
+								continue
+							}
+						}
 						if err := os.Remove(path); err != nil {
 							// Log error but continue
+						} else {
+							j.RecordOrLog(journal.OpDelete, path, group[0], "duplicate")
 						}
 					}
 				}
@@ -321,13 +377,61 @@ func (m Model) executeCmd() tea.Msg {
 	}
 
 	// Delete problematic files
+	cleanedUp := make(map[string]bool, len(m.filesToDelete))
 	for _, path := range m.filesToDelete {
+		if fileInfo, ok := byPath[path]; ok {
+			if err := lock.CheckUnchanged(fileInfo); err != nil {
+				conflict(path, err)
+				continue
+			}
+		}
 		if err := os.Remove(path); err != nil {
 			// Log error
+		} else {
+			cleanedUp[path] = true
+			j.RecordOrLog(journal.OpDelete, path, "", "cleanup")
 		}
 	}
 
-	return executeMsg{}
+	// Execute renames after deletions, so a kept copy can take over a deleted duplicate's name
+	for _, fileInfo := range m.cleanFiles {
+		if fileInfo.NewName != nil && cleanedUp[fileInfo.OriginalPath] {
+			// Corrupted files are planned for renaming too, but are gone now
+			fileInfo.NewName = nil
+		}
+		if fileInfo.NewName != nil {
+			if err := lock.CheckRename(fileInfo); err != nil {
+				conflict(fileInfo.OriginalPath, err)
+				fileInfo.NewName = nil
+				continue
+			}
+			if err := os.Rename(fileInfo.OriginalPath, fileInfo.NewPath); err != nil {
+				return errMsg(err)
+			}
+			if fileInfo.NewPath != fileInfo.OriginalPath {
+				j.RecordOrLog(journal.OpRename, fileInfo.OriginalPath, fileInfo.NewPath, "normalized")
+			}
+		}
+	}
+
+	// Refresh the library index with the kept files and any duplicates left in place
+	remaining := append([]*types.FileInfo{}, m.cleanFiles...)
+	kept := make(map[*types.FileInfo]bool, len(m.cleanFiles))
+	for _, fileInfo := range m.cleanFiles {
+		kept[fileInfo] = true
+	}
+	for _, fileInfo := range m.normalized {
+		if !kept[fileInfo] {
+			unrenamed := *fileInfo
+			unrenamed.NewName = nil
+			remaining = append(remaining, &unrenamed)
+		}
+	}
+	if err := index.Build(remaining, layout.Rel).Save(layout.IndexPath()); err != nil {
+		return errMsg(err)
+	}
+
+	return executeMsg{conflicts: conflicts}
 }
 
 func validatePDFHeader(filePath string) error {
@@ -6,17 +6,48 @@ import (
 
 // FileInfo represents information about a scanned file
 type FileInfo struct {
-	OriginalPath     string    `json:"original_path"`
-	OriginalName     string    `json:"original_name"`
-	Extension        string    `json:"extension"`
-	Size             uint64    `json:"size"`
-	ModifiedTime     time.Time `json:"modified_time"`
-	IsFailedDownload bool      `json:"is_failed_download"`
-	IsTooSmall       bool      `json:"is_too_small"`
-	NewName          *string   `json:"new_name,omitempty"`
-	NewPath          string    `json:"new_path"`
+	OriginalPath     string       `json:"original_path"`
+	OriginalName     string       `json:"original_name"`
+	Extension        string       `json:"extension"`
+	Size             uint64       `json:"size"`
+	ModifiedTime     time.Time    `json:"modified_time"`
+	IsFailedDownload bool         `json:"is_failed_download"`
+	IsTooSmall       bool         `json:"is_too_small"`
+	NewName          *string      `json:"new_name,omitempty"`
+	NewPath          string       `json:"new_path"`
+	Confidence       float64      `json:"confidence"`
+	Reasons          []ReasonCode `json:"reasons,omitempty"`
 }
 
+// ReasonCode is a stable identifier for why an operation or todo item was produced.
+// Values never change once released, so downstream tools can filter on them.
+type ReasonCode string
+
+const (
+	// Renames: which normalization steps changed the name
+	ReasonAlreadyNormalized      ReasonCode = "ALREADY_NORMALIZED"
+	ReasonDownloadSuffixRemoved  ReasonCode = "DOWNLOAD_SUFFIX_REMOVED"
+	ReasonSeriesPrefixRemoved    ReasonCode = "SERIES_PREFIX_REMOVED"
+	ReasonBracketsRemoved        ReasonCode = "BRACKETS_REMOVED"
+	ReasonNoiseStripped          ReasonCode = "NOISE_STRIPPED"
+	ReasonDuplicateMarkerRemoved ReasonCode = "DUPLICATE_MARKER_REMOVED"
+	ReasonParentheticalsRemoved  ReasonCode = "PARENTHETICALS_REMOVED"
+	ReasonYearExtracted          ReasonCode = "YEAR_EXTRACTED"
+	ReasonAuthorExtracted        ReasonCode = "AUTHOR_EXTRACTED"
+	ReasonMetadataMerged         ReasonCode = "METADATA_MERGED"
+
+	// Deletes
+	ReasonDupContentHash   ReasonCode = "DUP_CONTENT_HASH"
+	ReasonDupSameName      ReasonCode = "DUP_SAME_NAME"
+	ReasonIncompleteSuffix ReasonCode = "INCOMPLETE_SUFFIX"
+	ReasonCorruptHeader    ReasonCode = "CORRUPT_HEADER"
+	ReasonTooSmall         ReasonCode = "TOO_SMALL"
+
+	// Todo items
+	ReasonLowConfidence ReasonCode = "LOW_CONFIDENCE"
+	ReasonNearDupPages  ReasonCode = "NEAR_DUP_PAGES"
+)
+
 // ParsedMetadata represents parsed filename components
 type ParsedMetadata struct {
 	Authors *string `json:"authors,omitempty"`
@@ -29,18 +60,32 @@ type RenameOperation struct {
 	From   string `json:"from"`
 	To     string `json:"to"`
 	Reason string `json:"reason"`
+
+	ReasonCodes []ReasonCode `json:"reason_codes,omitempty"`
 }
 
 // DuplicateGroup represents a group of duplicate files
 type DuplicateGroup struct {
 	Keep   string   `json:"keep"`
 	Delete []string `json:"delete"`
+
+	ReasonCodes []ReasonCode `json:"reason_codes,omitempty"`
+}
+
+// NearDuplicate represents a copy of a book that differs only by a few extra pages
+type NearDuplicate struct {
+	Keep       string  `json:"keep"`
+	Duplicate  string  `json:"duplicate"`
+	ExtraPages int     `json:"extra_pages"`
+	Confidence float64 `json:"confidence"`
 }
 
 // DeleteOperation represents a file deletion operation
 type DeleteOperation struct {
 	Path  string `json:"path"`
 	Issue string `json:"issue"`
+
+	ReasonCodes []ReasonCode `json:"reason_codes,omitempty"`
 }
 
 // TodoItem represents a todo list item
@@ -48,6 +93,13 @@ type TodoItem struct {
 	Category string `json:"category"`
 	File     string `json:"file"`
 	Message  string `json:"message"`
+
+	// Set with --lang: the language-independent message and the values shown in it
+	MessageID string            `json:"message_id,omitempty"`
+	Params    map[string]string `json:"params,omitempty"`
+
+	// Set with --reason-codes
+	ReasonCodes []ReasonCode `json:"reason_codes,omitempty"`
 }
 
 // OperationsOutput represents the complete JSON output
@@ -56,6 +108,7 @@ type OperationsOutput struct {
 	DuplicateDeletes          []DuplicateGroup   `json:"duplicate_deletes"`
 	SmallOrCorruptedDeletes   []DeleteOperation  `json:"small_or_corrupted_deletes"`
 	TodoItems                 []TodoItem         `json:"todo_items"`
+	NearDuplicates            []NearDuplicate    `json:"near_duplicates,omitempty"`
 }
 
 // FileIssue represents different types of file issues
@@ -85,6 +138,18 @@ type Config struct {
 	AutoCleanup     bool
 	Json            bool
 	SkipCloudHash   bool
+	Sample          int
+	ConfidenceTiers bool
+	AutoThreshold   float64
+	ReviewThreshold float64
+	MergeMetadata   bool
+	NearDuplicates  bool
+	Lang            string
+	ReasonCodes     bool
+	Copy            string
+	OneLine         bool
+	Portable        bool
+	BreakLock       bool
 }
 
 // CleanupResult holds the result of cleanup operations
@@ -92,7 +157,9 @@ type CleanupResult struct {
 	DeletedIncomplete []string
 	DeletedCorrupted  []string
 	DeletedSmall      []string
+	DeletedDuplicates []string
 	FailedDeletions   []FailedDeletion
+	Conflicts         []Conflict
 }
 
 // FailedDeletion represents a failed file deletion
@@ -100,3 +167,9 @@ type FailedDeletion struct {
 	Path  string
 	Error string
 }
+
+// Conflict represents an operation skipped because the file changed after scanning
+type Conflict struct {
+	Path   string
+	Reason string
+}
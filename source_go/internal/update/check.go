@@ -0,0 +1,62 @@
+package update
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// CheckInterval is how long a version check result is reused before asking GitHub again
+const CheckInterval = 24 * time.Hour
+
+// DisableEnv turns off the background version check when set to any value
+const DisableEnv = "EBOOK_RENAMER_NO_UPDATE_CHECK"
+
+type cacheEntry struct {
+	CheckedAt time.Time `json:"checked_at"`
+	Latest    string    `json:"latest"`
+}
+
+// DefaultCachePath is where the last version check result is kept.
+// It is per user rather than per library, so the check runs once a day at most.
+func DefaultCachePath() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "ebook-renamer", "version-check.json"), nil
+}
+
+// Check returns the latest release tag if it is newer than current, or "" otherwise.
+// A result younger than CheckInterval is served from cachePath without network access.
+func Check(ctx context.Context, client *http.Client, cachePath, current string, now time.Time) (string, error) {
+	var entry cacheEntry
+	if data, err := os.ReadFile(cachePath); err == nil && json.Unmarshal(data, &entry) == nil && now.Sub(entry.CheckedAt) < CheckInterval {
+		return newerOrEmpty(current, entry.Latest), nil
+	}
+
+	release, err := Latest(ctx, client)
+	if err != nil {
+		return "", err
+	}
+
+	// Failing to cache only means checking again next run
+	entry = cacheEntry{CheckedAt: now, Latest: release.TagName}
+	if data, err := json.Marshal(entry); err == nil {
+		if err := os.MkdirAll(filepath.Dir(cachePath), 0755); err == nil {
+			os.WriteFile(cachePath, data, 0644)
+		}
+	}
+
+	return newerOrEmpty(current, release.TagName), nil
+}
+
+func newerOrEmpty(current, latest string) string {
+	if IsNewer(current, latest) {
+		return latest
+	}
+	return ""
+}
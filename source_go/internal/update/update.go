@@ -0,0 +1,239 @@
+package update
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+const (
+	// Repo is the GitHub repository releases are published to
+	Repo = "fpcMotif/ebook-renamer"
+	// ChecksumsAsset lists the sha256 of every release asset in sha256sum format.
+	// It is published alongside the binaries, so it catches corrupted downloads but not tampered releases.
+	ChecksumsAsset = "checksums.txt"
+)
+
+// apiBase is a variable so tests can point it at a local server
+var apiBase = "https://api.github.com"
+
+// Asset is a downloadable file attached to a release
+type Asset struct {
+	Name string `json:"name"`
+	URL  string `json:"browser_download_url"`
+}
+
+// Release is the subset of the GitHub release payload we use
+type Release struct {
+	TagName string  `json:"tag_name"`
+	HTMLURL string  `json:"html_url"`
+	Assets  []Asset `json:"assets"`
+}
+
+// Asset looks up a release asset by name
+func (r *Release) Asset(name string) (Asset, bool) {
+	for _, a := range r.Assets {
+		if a.Name == name {
+			return a, true
+		}
+	}
+	return Asset{}, false
+}
+
+// Latest fetches the latest published release
+func Latest(ctx context.Context, client *http.Client) (*Release, error) {
+	body, err := fetch(ctx, client, fmt.Sprintf("%s/repos/%s/releases/latest", apiBase, Repo))
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	var release Release
+	if err := json.NewDecoder(body).Decode(&release); err != nil {
+		return nil, fmt.Errorf("failed to decode release: %w", err)
+	}
+	if release.TagName == "" {
+		return nil, fmt.Errorf("release has no tag")
+	}
+	return &release, nil
+}
+
+// AssetName is the name of the binary asset built for the given platform
+func AssetName(goos, goarch string) string {
+	name := fmt.Sprintf("ebook-renamer_%s_%s", goos, goarch)
+	if goos == "windows" {
+		name += ".exe"
+	}
+	return name
+}
+
+// IsNewer reports whether latest is a higher version than current.
+// Development builds and unparseable versions never count as outdated.
+func IsNewer(current, latest string) bool {
+	cur, ok := parseVersion(current)
+	if !ok {
+		return false
+	}
+	next, ok := parseVersion(latest)
+	if !ok {
+		return false
+	}
+	for i := range cur.parts {
+		if next.parts[i] != cur.parts[i] {
+			return next.parts[i] > cur.parts[i]
+		}
+	}
+	// A final release is newer than its pre-releases
+	return cur.prerelease && !next.prerelease
+}
+
+type version struct {
+	parts      [3]int
+	prerelease bool
+}
+
+func parseVersion(s string) (version, bool) {
+	var v version
+	s = strings.TrimPrefix(strings.TrimSpace(s), "v")
+	if core, _, found := strings.Cut(s, "-"); found {
+		s = core
+		v.prerelease = true
+	}
+	fields := strings.Split(s, ".")
+	if len(fields) == 0 || len(fields) > 3 {
+		return v, false
+	}
+	for i, f := range fields {
+		n, err := strconv.Atoi(f)
+		if err != nil || n < 0 {
+			return v, false
+		}
+		v.parts[i] = n
+	}
+	return v, true
+}
+
+// ParseChecksums parses sha256sum output into a map of file name to lowercase hex digest
+func ParseChecksums(data []byte) map[string]string {
+	sums := make(map[string]string)
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		// sha256sum marks binary mode with a leading '*'
+		sums[strings.TrimPrefix(fields[1], "*")] = strings.ToLower(fields[0])
+	}
+	return sums
+}
+
+// Apply downloads the release binary for this platform, checks it against the
+// release checksums and replaces the executable at exePath with it.
+// The checksums come from the same release, so this is an integrity check only:
+// it catches a corrupted download, not a release an attacker has replaced.
+func Apply(ctx context.Context, client *http.Client, release *Release, exePath string) error {
+	name := AssetName(runtime.GOOS, runtime.GOARCH)
+	asset, ok := release.Asset(name)
+	if !ok {
+		return fmt.Errorf("release %s has no build for %s/%s", release.TagName, runtime.GOOS, runtime.GOARCH)
+	}
+	sumsAsset, ok := release.Asset(ChecksumsAsset)
+	if !ok {
+		return fmt.Errorf("release %s has no %s, refusing to install an unverified binary", release.TagName, ChecksumsAsset)
+	}
+
+	sumsBody, err := fetch(ctx, client, sumsAsset.URL)
+	if err != nil {
+		return err
+	}
+	sumsData, err := io.ReadAll(io.LimitReader(sumsBody, 1<<20))
+	sumsBody.Close()
+	if err != nil {
+		return fmt.Errorf("failed to read checksums: %w", err)
+	}
+	expected, ok := ParseChecksums(sumsData)[name]
+	if !ok {
+		return fmt.Errorf("%s has no entry for %s", ChecksumsAsset, name)
+	}
+
+	// Download next to the executable so the final rename stays on one filesystem
+	tmp, err := os.CreateTemp(filepath.Dir(exePath), ".ebook-renamer-update-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath)
+
+	body, err := fetch(ctx, client, asset.URL)
+	if err != nil {
+		tmp.Close()
+		return err
+	}
+	hash := sha256.New()
+	_, err = io.Copy(io.MultiWriter(tmp, hash), body)
+	body.Close()
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to download %s: %w", name, err)
+	}
+
+	if actual := hex.EncodeToString(hash.Sum(nil)); actual != expected {
+		return fmt.Errorf("checksum mismatch for %s: expected %s, got %s", name, expected, actual)
+	}
+
+	if err := os.Chmod(tmpPath, 0755); err != nil {
+		return err
+	}
+
+	// Windows cannot replace a running executable, but it can rename it
+	old := ""
+	if runtime.GOOS == "windows" {
+		old = exePath + ".old"
+		os.Remove(old)
+		if err := os.Rename(exePath, old); err != nil {
+			return fmt.Errorf("failed to move current executable: %w", err)
+		}
+	}
+	if err := os.Rename(tmpPath, exePath); err != nil {
+		if old != "" {
+			// Put the current executable back so the install is not left without one
+			if restoreErr := os.Rename(old, exePath); restoreErr != nil {
+				err = errors.Join(err, fmt.Errorf("failed to restore %s: %w", exePath, restoreErr))
+			}
+		}
+		return fmt.Errorf("failed to replace executable: %w", err)
+	}
+	return nil
+}
+
+func fetch(ctx context.Context, client *http.Client, url string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "ebook-renamer")
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	return resp.Body, nil
+}
@@ -0,0 +1,130 @@
+package update
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsNewer(t *testing.T) {
+	assert.True(t, IsNewer("v1.2.3", "v1.2.4"))
+	assert.True(t, IsNewer("1.2.3", "v1.10.0"))
+	assert.True(t, IsNewer("v1.3.0-rc1", "v1.3.0"))
+	assert.False(t, IsNewer("v1.3.0", "v1.3.0"))
+	assert.False(t, IsNewer("v1.3.0", "v1.3.0-rc1"))
+	assert.False(t, IsNewer("v2.0.0", "v1.9.9"))
+	// Development builds are never considered outdated
+	assert.False(t, IsNewer("dev", "v9.9.9"))
+	assert.False(t, IsNewer("v1.0.0", "nightly"))
+}
+
+func TestParseChecksums(t *testing.T) {
+	sums := ParseChecksums([]byte("ABC123  ebook-renamer_linux_amd64\ndef456 *ebook-renamer_windows_amd64.exe\n\nbroken line here\n"))
+	assert.Equal(t, map[string]string{
+		"ebook-renamer_linux_amd64":       "abc123",
+		"ebook-renamer_windows_amd64.exe": "def456",
+	}, sums)
+}
+
+// releaseServer serves a release whose binary asset has the given content and advertised checksum
+func releaseServer(t *testing.T, binary []byte, checksum string) *httptest.Server {
+	name := AssetName(runtime.GOOS, runtime.GOARCH)
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	mux.HandleFunc("/repos/"+Repo+"/releases/latest", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(Release{
+			TagName: "v1.5.0",
+			Assets: []Asset{
+				{Name: name, URL: server.URL + "/download/" + name},
+				{Name: ChecksumsAsset, URL: server.URL + "/download/" + ChecksumsAsset},
+			},
+		})
+	})
+	mux.HandleFunc("/download/"+name, func(w http.ResponseWriter, r *http.Request) {
+		w.Write(binary)
+	})
+	mux.HandleFunc("/download/"+ChecksumsAsset, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "%s  %s\n", checksum, name)
+	})
+
+	old := apiBase
+	apiBase = server.URL
+	t.Cleanup(func() { apiBase = old })
+	return server
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func TestApplyReplacesExecutable(t *testing.T) {
+	binary := []byte("new binary")
+	server := releaseServer(t, binary, sha256Hex(binary))
+
+	exe := filepath.Join(t.TempDir(), "ebook-renamer")
+	assert.NoError(t, os.WriteFile(exe, []byte("old binary"), 0755))
+
+	release, err := Latest(context.Background(), server.Client())
+	assert.NoError(t, err)
+	assert.Equal(t, "v1.5.0", release.TagName)
+
+	assert.NoError(t, Apply(context.Background(), server.Client(), release, exe))
+	data, err := os.ReadFile(exe)
+	assert.NoError(t, err)
+	assert.Equal(t, binary, data)
+}
+
+func TestApplyRejectsChecksumMismatch(t *testing.T) {
+	server := releaseServer(t, []byte("tampered binary"), sha256Hex([]byte("new binary")))
+
+	dir := t.TempDir()
+	exe := filepath.Join(dir, "ebook-renamer")
+	assert.NoError(t, os.WriteFile(exe, []byte("old binary"), 0755))
+
+	release, err := Latest(context.Background(), server.Client())
+	assert.NoError(t, err)
+	err = Apply(context.Background(), server.Client(), release, exe)
+	assert.ErrorContains(t, err, "checksum mismatch")
+
+	// The current executable is left alone and no temp file is left behind
+	data, _ := os.ReadFile(exe)
+	assert.Equal(t, "old binary", string(data))
+	entries, _ := os.ReadDir(dir)
+	assert.Len(t, entries, 1)
+}
+
+func TestCheckUsesCache(t *testing.T) {
+	server := releaseServer(t, nil, "")
+	cachePath := filepath.Join(t.TempDir(), "version-check.json")
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	latest, err := Check(context.Background(), server.Client(), cachePath, "v1.4.0", now)
+	assert.NoError(t, err)
+	assert.Equal(t, "v1.5.0", latest)
+
+	// Within the interval the cached answer is used even if GitHub is unreachable
+	server.Close()
+	latest, err = Check(context.Background(), server.Client(), cachePath, "v1.5.0", now.Add(time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, "", latest)
+	latest, err = Check(context.Background(), server.Client(), cachePath, "v1.4.0", now.Add(time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, "v1.5.0", latest)
+
+	_, err = Check(context.Background(), server.Client(), cachePath, "v1.4.0", now.Add(CheckInterval))
+	assert.Error(t, err)
+}